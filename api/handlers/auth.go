@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// Authorizer authenticates requests and checks the role and studio of the caller before
+// the route is served. Without configured credentials every request is served.
+type Authorizer struct {
+	authenticator *auth.Authenticator
+}
+
+// NewAuthorizer creates an authorizer which authenticates requests with the authenticator
+func NewAuthorizer(authenticator *auth.Authenticator) *Authorizer {
+	return &Authorizer{authenticator: authenticator}
+}
+
+// Require serves the request with next when the caller has one of the roles in the studio of
+// the studioID path variable, routes without the variable belong to the default studio
+func (a *Authorizer) Require(next http.HandlerFunc, roles ...auth.Role) http.HandlerFunc {
+	return a.require(next, func(p auth.Principal, r *http.Request) bool {
+		studioID := processors.DefaultStudioID
+		if value, ok := mux.Vars(r)["studioID"]; ok {
+			studioID, _ = strconv.Atoi(value)
+		}
+		return p.HasRole(roles...) && p.CanAccessStudio(studioID)
+	})
+}
+
+// RequireAllStudios serves the request with next when the caller is an owner whose credentials
+// are not bound to a single studio, these manage the studio registry
+func (a *Authorizer) RequireAllStudios(next http.HandlerFunc) http.HandlerFunc {
+	return a.require(next, func(p auth.Principal, r *http.Request) bool {
+		return p.HasRole(auth.RoleOwner) && p.StudioID == 0
+	})
+}
+
+// require authenticates the request and serves it with next when allowed accepts the caller
+func (a *Authorizer) require(next http.HandlerFunc, allowed func(auth.Principal, *http.Request) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.authenticator.Enabled() {
+			next(w, r)
+			return
+		}
+
+		principal, err := a.authenticator.Authenticate(r)
+		if err != nil {
+			SendErrorResponse(w, r, err)
+			return
+		}
+		if !allowed(principal, r) {
+			SendErrorResponse(w, r, errForbidden)
+			return
+		}
+		next(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}
+}
+
+// actingMember returns the registered member of a request made with member credentials,
+// restricted is false for owners and unauthenticated requests which may act for any member
+func (h *Handler) actingMember(r *http.Request) (member structs.Member, restricted bool, err error) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok || principal.Role != auth.RoleMember {
+		return structs.Member{}, false, nil
+	}
+
+	member, err = h.members.GetMember(principal.MemberID)
+	if errors.Is(err, processors.ErrMemberNotFound) {
+		err = errUnregisteredMember
+	}
+	return member, true, err
+}
+
+// checkActingMember writes the response for a request whose member cannot act and reports
+// whether the request may go on, allowed reports whether the member may act on the resource
+func (h *Handler) checkActingMember(w http.ResponseWriter, r *http.Request, allowed func(structs.Member) bool) bool {
+	member, restricted, err := h.actingMember(r)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return false
+	}
+	if restricted && !allowed(member) {
+		utils.FromContext(r.Context()).Warn("member refused access", "member_id", member.ID, "method", r.Method, "path", r.URL.Path)
+		SendErrorResponse(w, r, errNotOwnResource)
+		return false
+	}
+	return true
+}
+
+// ownsBooking reports whether the booking is of the member
+func ownsBooking(member structs.Member, booking structs.Booking) bool {
+	return booking.MemberID == member.ID ||
+		structs.NormalizeMemberName(booking.MemberName) == structs.NormalizeMemberName(member.Name)
+}
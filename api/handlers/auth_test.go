@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+const testJWTKey = "test-key"
+
+// newTestAuthorizer creates an authorizer accepting JWTs signed with testJWTKey
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	t.Helper()
+
+	authenticator, err := auth.NewAuthenticator([][]byte{[]byte(testJWTKey)}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return NewAuthorizer(authenticator)
+}
+
+// executeAuthRequest performs the request with the token against the routes guarded by the authorizer
+func executeAuthRequest(a *Authorizer, s *StudiosHandler, token string, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	r := mux.NewRouter()
+	owner, anyone := []auth.Role{auth.RoleOwner}, []auth.Role{auth.RoleOwner, auth.RoleMember}
+
+	r.HandleFunc("/studios", a.RequireAllStudios(s.CreateStudioHandler)).Methods(http.MethodPost)
+	for _, prefix := range []string{"", "/studios/{studioID:[0-9]+}"} {
+		r.HandleFunc(prefix+"/classes", a.Require(s.Scoped((*Handler).CreateClassHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/classes", a.Require(s.Scoped((*Handler).GetClassesHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/bookings", a.Require(s.Scoped((*Handler).BookClassHandler), anyone...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/bookings", a.Require(s.Scoped((*Handler).GetBookingsHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/bookings/{id:[0-9]+}", a.Require(s.Scoped((*Handler).CancelBookingHandler), anyone...)).Methods(http.MethodDelete)
+		r.HandleFunc(prefix+"/members", a.Require(s.Scoped((*Handler).CreateMemberHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}", a.Require(s.Scoped((*Handler).GetMemberHandler), anyone...)).Methods(http.MethodGet)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+// testToken signs a JWT for the principal with testJWTKey
+func testToken(t *testing.T, principal auth.Principal) string {
+	t.Helper()
+
+	claims := auth.Claims{Principal: principal, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTKey))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return token
+}
+
+// registerTestMembers registers the members with the owner token
+func registerTestMembers(t *testing.T, a *Authorizer, s *StudiosHandler, ownerToken string, names ...string) []structs.Member {
+	t.Helper()
+
+	var members []structs.Member
+	for _, name := range names {
+		req, _ := http.NewRequest("POST", "/members", bytes.NewBuffer([]byte(fmt.Sprintf(`{"name": "%s", "email": "member@example.com"}`, name))))
+		response := executeAuthRequest(a, s, ownerToken, req)
+		checkResponseCode(t, http.StatusCreated, response.Code)
+
+		var member structs.Member
+		json.Unmarshal(response.Body.Bytes(), &member)
+		members = append(members, member)
+	}
+	return members
+}
+
+func TestAuthorizer_Roles(t *testing.T) {
+	a, studios := newTestAuthorizer(t), newTestStudiosHandler(t)
+	ownerToken := testToken(t, auth.Principal{Role: auth.RoleOwner})
+
+	// Requests without a valid token are rejected
+	req, _ := http.NewRequest("GET", "/classes", nil)
+	response := executeAuthRequest(a, studios, "", req)
+	checkResponseCode(t, http.StatusUnauthorized, response.Code)
+	if response.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected a WWW-Authenticate header")
+	}
+	req, _ = http.NewRequest("GET", "/classes", nil)
+	checkResponseCode(t, http.StatusUnauthorized, executeAuthRequest(a, studios, "not-a-token", req).Code)
+
+	// Only owners register members and create classes
+	members := registerTestMembers(t, a, studios, ownerToken, "Sai Kumar", "John")
+	saiToken := testToken(t, auth.Principal{Role: auth.RoleMember, MemberID: members[0].ID})
+
+	classBody := fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 5}`, futureDate(5), futureDate(6))
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, saiToken, req).Code)
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeAuthRequest(a, studios, ownerToken, req).Code)
+
+	// Members can list classes but only see their own details
+	req, _ = http.NewRequest("GET", "/classes", nil)
+	checkResponseCode(t, http.StatusOK, executeAuthRequest(a, studios, saiToken, req).Code)
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/members/%d", members[1].ID), nil)
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, saiToken, req).Code)
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/members/%d", members[0].ID), nil)
+	checkResponseCode(t, http.StatusOK, executeAuthRequest(a, studios, saiToken, req).Code)
+
+	// Only owners not bound to a studio register studios
+	req, _ = http.NewRequest("POST", "/studios", bytes.NewBuffer([]byte(`{"name": "Dublin", "timezone": "Europe/Dublin"}`)))
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, testToken(t, auth.Principal{Role: auth.RoleOwner, StudioID: 1}), req).Code)
+	req, _ = http.NewRequest("POST", "/studios", bytes.NewBuffer([]byte(`{"name": "Dublin", "timezone": "Europe/Dublin"}`)))
+	checkResponseCode(t, http.StatusCreated, executeAuthRequest(a, studios, ownerToken, req).Code)
+
+	// Credentials of one studio do not reach another
+	req, _ = http.NewRequest("GET", "/studios/2/classes", nil)
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, saiToken, req).Code)
+	req, _ = http.NewRequest("GET", "/studios/2/classes", nil)
+	checkResponseCode(t, http.StatusOK, executeAuthRequest(a, studios, ownerToken, req).Code)
+
+	// A member token of an unregistered member cannot book
+	payload := fmt.Sprintf(`{"class_name": "Yoga", "class_date": "%s"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, testToken(t, auth.Principal{Role: auth.RoleMember, MemberID: 99}), req).Code)
+}
+
+func TestAuthorizer_MemberActsForThemselves(t *testing.T) {
+	a, studios := newTestAuthorizer(t), newTestStudiosHandler(t)
+	ownerToken := testToken(t, auth.Principal{Role: auth.RoleOwner})
+
+	members := registerTestMembers(t, a, studios, ownerToken, "Sai Kumar", "John")
+	saiToken := testToken(t, auth.Principal{Role: auth.RoleMember, MemberID: members[0].ID})
+	johnToken := testToken(t, auth.Principal{Role: auth.RoleMember, MemberID: members[1].ID})
+
+	classBody := fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 5}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeAuthRequest(a, studios, ownerToken, req).Code)
+
+	// The member of the token books, whoever the body names
+	payload := fmt.Sprintf(`{"class_name": "Yoga", "class_date": "%s", "member_name": "John", "member_id": %d}`, futureDate(5), members[1].ID)
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeAuthRequest(a, studios, saiToken, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.MemberID != members[0].ID || booking.MemberName != "Sai Kumar" {
+		t.Errorf("Expected the booking of Sai Kumar, got %v", response.Body.String())
+	}
+	if response.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected no deprecation header for a booking from a member token")
+	}
+
+	// Members cannot cancel the bookings of others
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	checkResponseCode(t, http.StatusForbidden, executeAuthRequest(a, studios, johnToken, req).Code)
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	checkResponseCode(t, http.StatusOK, executeAuthRequest(a, studios, saiToken, req).Code)
+
+	// Owners book for the member of the body
+	payload = fmt.Sprintf(`{"class_name": "Yoga", "class_date": "%s", "member_id": %d}`, futureDate(5), members[1].ID)
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response = executeAuthRequest(a, studios, ownerToken, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.MemberID != members[1].ID {
+		t.Errorf("Expected the booking of John, got %v", response.Body.String())
+	}
+
+	// Members only list their own bookings, whichever member the query names
+	req, _ = http.NewRequest("GET", "/bookings?member=John", nil)
+	response = executeAuthRequest(a, studios, saiToken, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var page structs.BookingPage
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if len(page.Bookings) != 0 {
+		t.Errorf("Expected no bookings of Sai Kumar, got %v", response.Body.String())
+	}
+	req, _ = http.NewRequest("GET", "/bookings", nil)
+	response = executeAuthRequest(a, studios, johnToken, req)
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if len(page.Bookings) != 1 || page.Bookings[0].MemberID != members[1].ID {
+		t.Errorf("Expected the booking of John, got %v", response.Body.String())
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,9 +20,12 @@ import (
 var validate *validator.Validate
 
 type BookingRequest struct {
-	ClassName  string `json:"class_name" validate:"required"`
-	MemberName string `json:"member_name" validate:"required"`
-	ClassDate  string `json:"class_date" validate:"required,dateformat"`
+	ClassName string `json:"class_name" validate:"required"`
+	MemberID  int    `json:"member_id"`
+	// Deprecated: book with the member_id of a registered member instead
+	MemberName string `json:"member_name" validate:"required_without=MemberID"`
+	ClassDate  string `json:"class_date" validate:"required,sessionformat"` //YYYY-MM-DD or YYYY-MM-DDTHH:MM for scheduled sessions
+	Waitlist   bool   `json:"waitlist"`                                     //join the waitlist when the class is full
 }
 
 // validateDateFormat checks if the date is in the format YYYY-MM-DD
@@ -30,103 +35,334 @@ func validateDateFormat(fl validator.FieldLevel) bool {
 	return err == nil
 }
 
+// validateTimeFormat checks if the time of day is in the format HH:MM
+func validateTimeFormat(fl validator.FieldLevel) bool {
+	_, err := time.Parse(processors.TIMEFORMAT, fl.Field().String())
+	return err == nil
+}
+
+// validateSessionFormat checks if the session is a date or a date and start time
+func validateSessionFormat(fl validator.FieldLevel) bool {
+	_, _, err := parseSession(fl.Field().String(), time.UTC)
+	return err == nil
+}
+
+// validateTimezone checks if the timezone is a known IANA timezone
+func validateTimezone(fl validator.FieldLevel) bool {
+	_, err := time.LoadLocation(fl.Field().String())
+	return err == nil
+}
+
+// parseSession parses the start of a session in the location and reports whether a start time
+// was given, a date alone is the all day session of classes without schedule
+func parseSession(value string, location *time.Location) (time.Time, bool, error) {
+	if session, err := time.ParseInLocation(SESSIONFORMAT, value, location); err == nil {
+		return session, true, nil
+	}
+	date, err := time.ParseInLocation(DATEFORMAT, value, location)
+	return date, false, err
+}
+
 func init() {
 	// Initialize the validator
 	//we use validator to validate the input request after unmarshalling
 	validate = validator.New()
 
+	// Report fields by the name clients send them with
+	validate.RegisterTagNameFunc(jsonFieldName)
+
 	// Register custom validation for the date format
 	//this helps in perfoming validation on datetime w.r.t format
 	validate.RegisterValidation("dateformat", validateDateFormat)
+	validate.RegisterValidation("timeformat", validateTimeFormat)
+	validate.RegisterValidation("sessionformat", validateSessionFormat)
+	validate.RegisterValidation("timezone", validateTimezone)
 }
 
-func SendErrorResponse(w http.ResponseWriter, message string, details string, statusCode int) {
+// BookClassHandler handles booking a class for a specific date
+func (h *Handler) BookClassHandler(w http.ResponseWriter, r *http.Request) {
+	var request BookingRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		h.rejectBooking(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Surrounding spaces are not part of the member name
+	request.MemberName = strings.TrimSpace(request.MemberName)
+
+	// Members always book for themselves, the member of the body is only used for owners
+	member, restricted, err := h.actingMember(r)
+	if err != nil {
+		h.rejectBooking(w, r, err)
+		return
+	}
+	if restricted {
+		request.MemberID, request.MemberName = member.ID, member.Name
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		h.rejectBooking(w, r, err)
+		return
+	}
+
+	// Parse the class date and the start time of the session, it is validated already
+	classDate, timed, err := parseSession(request.ClassDate, h.location)
+
+	//check whether classDate provided is not past date in the studio's timezone, sessions
+	//with a start time cannot be booked once they started
+	if err == nil && (classDate.Before(h.today()) || (timed && classDate.Before(time.Now()))) {
+		fields.add("class_date", processors.ErrBookingInPast.Code, "class_date cannot be a past date or a session which already started")
+	}
+	if len(fields) > 0 {
+		h.metrics.bookingRejected(h.studioID, errInvalidRequest)
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	// Book for the registered member when an id is given, the member name is a deprecated fallback
+	if request.MemberID != 0 {
+		member, err := h.members.GetMember(request.MemberID)
+		if err != nil {
+			h.rejectBooking(w, r, err)
+			return
+		}
+		request.MemberName = member.Name
+	} else {
+		w.Header().Set("Deprecation", "true")
+		utils.FromContext(r.Context()).Warn("deprecated booking by member_name, use member_id", "member_name", request.MemberName)
+	}
+
+	// Call the booking service to create a booking, members who asked for it
+	// are put on the waitlist when the class is full
+	var booking structs.Booking
+	if request.Waitlist {
+		booking, err = h.bookings.JoinWaitlist(strings.ToLower(request.ClassName), request.MemberName, classDate)
+	} else {
+		booking, err = h.bookings.BookClass(strings.ToLower(request.ClassName), request.MemberName, classDate)
+	}
+	if err != nil {
+		h.rejectBooking(w, r, err)
+		return
+	}
+
+	h.metrics.bookingCreated(h.studioID, booking)
+
+	statusCode := http.StatusOK
+	if booking.Status == structs.BookingStatusWaitlisted {
+		statusCode = http.StatusAccepted
+		utils.FromContext(r.Context()).Info("member waitlisted", "member_name", request.MemberName, "position", booking.WaitlistPosition, "class_name", request.ClassName, "class_date", classDate)
+	} else {
+		utils.FromContext(r.Context()).Info("booking confirmed", "member_name", request.MemberName, "class_name", request.ClassName, "class_date", classDate)
+	}
+
+	// Return the created booking as a response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(booking)
 
-	errorResponse := structs.ErrorResponse{
-		Error:   message,
-		Details: details,
-		Status:  statusCode,
-	}
+}
 
-	utils.ErrorLogger.Println(errorResponse)
-	// Encode the error response as JSON and write it to the response writer
-	json.NewEncoder(w).Encode(errorResponse)
+// rejectBooking responds with the error of a booking request which failed and counts the rejection
+func (h *Handler) rejectBooking(w http.ResponseWriter, r *http.Request, err error) {
+	h.metrics.bookingRejected(h.studioID, err)
+	SendErrorResponse(w, r, err)
 }
 
-// BookClassHandler handles booking a class for a specific date
-func BookClassHandler(w http.ResponseWriter, r *http.Request) {
-	var request BookingRequest
+// bookingSorts are the sort orders of GetBookingsHandler
+var bookingSorts = []string{
+	processors.SortByClassDate, "-" + processors.SortByClassDate,
+	processors.SortByClassName, "-" + processors.SortByClassName,
+	processors.SortByID, "-" + processors.SortByID,
+}
 
-	err := json.NewDecoder(r.Body).Decode(&request)
+// GetBookingsHandler handles listing the confirmed bookings page by page, filtered by the
+// from and to dates, class name and member. Members only see their own bookings.
+func (h *Handler) GetBookingsHandler(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	query := processors.BookingQuery{
+		ClassName:  strings.ToLower(strings.TrimSpace(values.Get("class_name"))),
+		MemberName: strings.TrimSpace(values.Get("member")),
+		Sort:       values.Get("sort"),
+		Cursor:     values.Get("cursor"),
+	}
+
+	// Every invalid parameter is reported at once
+	var fields fieldErrors
+	var err error
+	if value := values.Get("from"); value != "" {
+		if query.From, err = h.parseDate(value); err != nil {
+			fields.add("from", "dateformat", "from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if value := values.Get("to"); value != "" {
+		if query.To, err = h.parseDate(value); err != nil {
+			fields.add("to", "dateformat", "to must be a date in YYYY-MM-DD format")
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		fields.add("from", "ltefield", "from cannot be after to")
+	}
+	if query.Sort != "" && !slices.Contains(bookingSorts, query.Sort) {
+		fields.add("sort", "oneof", fmt.Sprintf("sort must be one of %s", strings.Join(bookingSorts, " ")))
+	}
+	if value := values.Get("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit < 1 || query.Limit > processors.MaxPageSize {
+			fields.add("limit", "max", fmt.Sprintf("limit must be a number from 1 to %d", processors.MaxPageSize))
+		}
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	// Members can only list their own bookings
+	member, restricted, err := h.actingMember(r)
 	if err != nil {
-		SendErrorResponse(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		SendErrorResponse(w, r, err)
 		return
 	}
+	if restricted {
+		query.MemberName = member.Name
+	}
 
-	// Validate the request fields
-	err = validate.Struct(request)
+	page, err := h.bookings.ListBookings(query)
 	if err != nil {
-		// If validation fails, extract validation errors and return specific error messages
-		validationErrors := err.(validator.ValidationErrors)
-		for _, e := range validationErrors {
-			// Return a clear message indicating the missing field or invalid date format
-			errorMessage := fmt.Sprintf("%s is missing or invalid", e.Field())
-			SendErrorResponse(w, "Invalid Data", errorMessage, http.StatusBadRequest)
-			return
-		}
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetBookingsByDateHandler handles fetching the bookings of a class date, it is an alias of
+// GET /bookings with from and to set to the date
+func (h *Handler) GetBookingsByDateHandler(w http.ResponseWriter, r *http.Request) {
+	classDate := mux.Vars(r)["classDate"]
+
+	r = r.Clone(r.Context())
+	values := r.URL.Query()
+	values.Set("from", classDate)
+	values.Set("to", classDate)
+	r.URL.RawQuery = values.Encode()
+	h.GetBookingsHandler(w, r)
+}
+
+// GetBookingHandler handles fetching a booking by its id
+func (h *Handler) GetBookingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
 	}
 
-	// Parse the class date
-	classDate, err := time.Parse(DATEFORMAT, request.ClassDate)
+	booking, err := h.bookings.GetBooking(id)
 	if err != nil {
-		SendErrorResponse(w, "Invalid Data", "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		SendErrorResponse(w, r, err)
 		return
 	}
+	if !h.checkActingMember(w, r, func(member structs.Member) bool { return ownsBooking(member, booking) }) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(booking)
+}
 
-	//check whether classDate provided is not past date
-	if classDate.Before(time.Now().Truncate(24 * time.Hour)) {
-		SendErrorResponse(w, "Invalid Data", "Invalid date. booking cannot be less than today", http.StatusBadRequest)
+// CancelBookingHandler handles cancelling a booking by its id, the released spot
+// is given to the first member on the waitlist
+func (h *Handler) CancelBookingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
 		return
 	}
 
-	// Call the booking service to create a booking
-	booking, err := processors.BookClass(strings.ToLower(request.ClassName), request.MemberName, classDate)
+	// Members can only cancel their own bookings
+	booking, err := h.bookings.GetBooking(id)
+	if err == nil && !h.checkActingMember(w, r, func(member structs.Member) bool { return ownsBooking(member, booking) }) {
+		return
+	}
+	if err == nil {
+		booking, err = h.bookings.CancelBookingByID(id)
+	}
 	if err != nil {
-		SendErrorResponse(w, "Unable to Process Request", err.Error(), http.StatusInternalServerError)
+		SendErrorResponse(w, r, err)
 		return
 	}
 
-	utils.InfoLogger.Printf("Booking for class %s confirmed for user %s on %s", request.ClassName, request.MemberName, classDate)
+	utils.FromContext(r.Context()).Info("booking cancelled", "booking_id", booking.ID, "member_name", booking.MemberName, "class_name", booking.ClassName, "class_date", booking.ClassDate)
 
-	// Return the created booking as a response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(booking)
+}
+
+// MarkNoShowHandler handles recording that the member of a booking did not attend the
+// session, the member is given a strike
+func (h *Handler) MarkNoShowHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	booking, err := h.bookings.MarkNoShow(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("booking marked as no-show", "booking_id", booking.ID, "member_name", booking.MemberName, "class_name", booking.ClassName, "class_date", booking.ClassDate)
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(booking)
 }
 
-// GetBookingsByDateHandler handles fetching bookings for a specific class date
-func GetBookingsByDateHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	classDateStr := vars["classDate"]
+// CheckInHandler handles checking in the member of a booking at the front desk, check-ins
+// are accepted around the start of the session
+func (h *Handler) CheckInHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
 
-	// Parse the class date
-	classDate, err := time.Parse(DATEFORMAT, classDateStr)
+	booking, err := h.bookings.CheckIn(id)
 	if err != nil {
-		SendErrorResponse(w, "Invalid date format. Use YYYY-MM-DD", err.Error(), http.StatusBadRequest)
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("member checked in", "booking_id", booking.ID, "member_name", booking.MemberName, "class_name", booking.ClassName, "class_date", booking.ClassDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(booking)
+}
+
+// GetMemberBookingsHandler handles fetching the schedule of a member
+func (h *Handler) GetMemberBookingsHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if !h.checkActingMember(w, r, func(member structs.Member) bool {
+		return structs.NormalizeMemberName(member.Name) == structs.NormalizeMemberName(name)
+	}) {
 		return
 	}
 
-	// Call the service to fetch bookings
-	bookings, err := processors.GetBookingsByDate(classDate)
+	bookings, err := h.bookings.GetMemberBookings(name)
 	if err != nil {
-		SendErrorResponse(w, "", err.Error(), http.StatusNotFound)
+		SendErrorResponse(w, r, err)
 		return
 	}
 
-	// Return the bookings as a response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(bookings)
@@ -3,11 +3,16 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
 	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 
 	"github.com/gorilla/mux"
@@ -15,19 +20,72 @@ import (
 
 var errorResponse structs.ErrorResponse
 
+// testStore is shared by all handler tests, some cases rely on classes created by earlier ones
+var testStore = storage.NewMemoryStore()
+
+var testHandler = newTestHandler(testStore, time.Local)
+
+// newTestHandler creates a handler with processors on top of the store for a studio in the location
+func newTestHandler(store storage.Store, location *time.Location) *Handler {
+	classProcessor := processors.NewClassProcessor(store, store, store)
+	memberProcessor := processors.NewMemberProcessor(store)
+	return NewHandler(classProcessor, processors.NewBookingProcessor(classProcessor, memberProcessor, store), memberProcessor, location)
+}
+
 // executeRequest will create a mux router to perform the test cases
 func executeRequest(req *http.Request) *httptest.ResponseRecorder {
+	return executeRequestWith(testHandler, req)
+}
+
+// executeJSON performs a request with the JSON body against the routes of the handler
+func executeJSON(handler *Handler, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+	return executeRequestWith(handler, req)
+}
+
+// executeRequestWith performs the request against the routes of the handler
+func executeRequestWith(testHandler *Handler, req *http.Request) *httptest.ResponseRecorder {
 	rr := httptest.NewRecorder()
 	r := mux.NewRouter()
 
 	// Route to create a new class
-	r.HandleFunc("/classes", CreateClassHandler).Methods(http.MethodPost)
-	r.HandleFunc("/bookings", BookClassHandler).Methods(http.MethodPost)
-	r.HandleFunc("/bookings/{classDate}", GetBookingsByDateHandler).Methods("GET")
+	r.HandleFunc("/classes", testHandler.CreateClassHandler).Methods(http.MethodPost)
+	r.HandleFunc("/classes", testHandler.GetClassesHandler).Methods(http.MethodGet)
+	r.HandleFunc("/classes/{id}", testHandler.GetClassHandler).Methods(http.MethodGet)
+	r.HandleFunc("/classes/{id}", testHandler.UpdateClassHandler).Methods(http.MethodPatch)
+	r.HandleFunc("/classes/{id}", testHandler.DeleteClassHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/classes/{id}/occupancy", testHandler.GetClassOccupancyHandler).Methods(http.MethodGet)
+	r.HandleFunc("/occupancy/{classDate:[0-9]{4}-[0-9]{2}-[0-9]{2}}", testHandler.GetOccupancyByDateHandler).Methods(http.MethodGet)
+	r.HandleFunc("/classes/{id}/sessions/{session}/roster", testHandler.GetRosterHandler).Methods(http.MethodGet)
+	r.HandleFunc("/instructors", testHandler.CreateInstructorHandler).Methods(http.MethodPost)
+	r.HandleFunc("/instructors", testHandler.GetInstructorsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/instructors/{id:[0-9]+}", testHandler.GetInstructorHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rooms", testHandler.CreateRoomHandler).Methods(http.MethodPost)
+	r.HandleFunc("/rooms", testHandler.GetRoomsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rooms/{id:[0-9]+}", testHandler.GetRoomHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bookings", testHandler.BookClassHandler).Methods(http.MethodPost)
+	r.HandleFunc("/bookings", testHandler.GetBookingsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bookings/{classDate:[0-9]{4}-[0-9]{2}-[0-9]{2}}", testHandler.GetBookingsByDateHandler).Methods("GET")
+	r.HandleFunc("/bookings/{id:[0-9]+}", testHandler.GetBookingHandler).Methods(http.MethodGet)
+	r.HandleFunc("/bookings/{id:[0-9]+}", testHandler.CancelBookingHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/bookings/{id:[0-9]+}/check-in", testHandler.CheckInHandler).Methods(http.MethodPost)
+	r.HandleFunc("/bookings/{id:[0-9]+}/no-show", testHandler.MarkNoShowHandler).Methods(http.MethodPost)
+	r.HandleFunc("/members", testHandler.CreateMemberHandler).Methods(http.MethodPost)
+	r.HandleFunc("/members/{id:[0-9]+}", testHandler.GetMemberHandler).Methods(http.MethodGet)
+	r.HandleFunc("/members/{id:[0-9]+}", testHandler.UpdateMemberHandler).Methods(http.MethodPatch)
+	r.HandleFunc("/members/{id:[0-9]+}/strikes", testHandler.ResetStrikesHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/members/{id:[0-9]+}/membership", testHandler.AssignMembershipHandler).Methods(http.MethodPut)
+	r.HandleFunc("/members/{id:[0-9]+}/balance", testHandler.GetBalanceHandler).Methods(http.MethodGet)
+	r.HandleFunc("/members/{name}/bookings", testHandler.GetMemberBookingsHandler).Methods(http.MethodGet)
 	r.ServeHTTP(rr, req)
 	return rr
 }
 
+// futureDate returns the date which is the given number of days from today in YYYY-MM-DD format
+func futureDate(days int) string {
+	return time.Now().AddDate(0, 0, days).Format(DATEFORMAT)
+}
+
 // function checkResponseCode used to verifiy the expected and actual status codes
 func checkResponseCode(t *testing.T, expected, actual int) {
 	if expected != actual {
@@ -36,7 +94,11 @@ func checkResponseCode(t *testing.T, expected, actual int) {
 }
 
 func TestBookClass_Success(t *testing.T) {
-	payload := `{"member_name":"Sai Kumar", "class_date":"2025-02-15", "class_name": "Yoga"}`
+	classBody := fmt.Sprintf(`{"class_name": "Zumba", "start_date": "%s", "end_date": "%s", "capacity": 10}`, futureDate(5), futureDate(15))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req).Code)
+
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Zumba"}`, futureDate(10))
 	req, err := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
 	if err != nil {
 		t.Fatal(err.Error())
@@ -53,6 +115,23 @@ func TestBookClass_Success(t *testing.T) {
 	}
 }
 
+func TestBookClass_ClassNotScheduled(t *testing.T) {
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Kickboxing"}`, futureDate(10))
+	req, err := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+
+	json.Unmarshal(response.Body.Bytes(), &errorResponse)
+	if !strings.Contains(errorResponse.Details, "class is not scheduled on the selected date") {
+		t.Errorf("Expected 'class is not scheduled on the selected date' error, got %v", errorResponse.Details)
+	}
+}
+
 func TestBookClass_InvalidJSON(t *testing.T) {
 	payload := `{"member_name":"Sai Kumar", "class_date":"2025-02-15"`
 	req, err := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
@@ -77,10 +156,7 @@ func TestBookClass_MissingMemberName(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
-	json.Unmarshal(response.Body.Bytes(), &errorResponse)
-	if !strings.Contains(errorResponse.Details, "MemberName is missing or invalid") {
-		t.Errorf("Expected 'MemberName is missing or invalid' error, got %v", response.Body.String())
-	}
+	checkFieldError(t, response, "member_name", "required_without")
 }
 
 func TestBookClass_InvalidDateFormat(t *testing.T) {
@@ -94,10 +170,7 @@ func TestBookClass_InvalidDateFormat(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
-	json.Unmarshal(response.Body.Bytes(), &errorResponse)
-	if !strings.Contains(errorResponse.Details, "ClassDate is missing or invalid") {
-		t.Errorf("Expected 'ClassDate is missing or invalid' error, got %v", errorResponse.Details)
-	}
+	checkFieldError(t, response, "class_date", "sessionformat")
 }
 
 func TestBookClass_InvalidClassDate(t *testing.T) {
@@ -110,10 +183,7 @@ func TestBookClass_InvalidClassDate(t *testing.T) {
 
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
-	json.Unmarshal(response.Body.Bytes(), &errorResponse)
-	if !strings.Contains(errorResponse.Details, "Invalid date. booking cannot be less than today") {
-		t.Errorf("Expected 'Invalid date. booking cannot be less than today' error, got %v", errorResponse.Details)
-	}
+	checkFieldError(t, response, "class_date", "booking_in_past")
 }
 
 func TestBookClass_EmptyMemberName(t *testing.T) {
@@ -126,8 +196,415 @@ func TestBookClass_EmptyMemberName(t *testing.T) {
 
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "member_name", "required_without")
+}
+
+func TestBookClass_ClassFull(t *testing.T) {
+	classBody := fmt.Sprintf(`{"class_name": "Spin", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req).Code)
+
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Spin"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	payload = fmt.Sprintf(`{"member_name":"John", "class_date":"%s", "class_name": "Spin"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+
 	json.Unmarshal(response.Body.Bytes(), &errorResponse)
-	if !strings.Contains(errorResponse.Details, "MemberName is missing or invalid") {
-		t.Errorf("Expected 'MemberName is missing or invalid' error, got %v", errorResponse.Details)
+	if !strings.Contains(errorResponse.Details, "class is fully booked") {
+		t.Errorf("Expected 'class is fully booked' error, got %v", errorResponse.Details)
+	}
+}
+
+func TestBookClass_Waitlisted(t *testing.T) {
+	classBody := fmt.Sprintf(`{"class_name": "Barre", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req).Code)
+
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Barre"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	payload = fmt.Sprintf(`{"member_name":"John", "class_date":"%s", "class_name": "Barre", "waitlist": true}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusAccepted, response.Code)
+
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.Status != structs.BookingStatusWaitlisted || booking.WaitlistPosition != 1 {
+		t.Errorf("Expected waitlisted booking at position 1, got %v", response.Body.String())
+	}
+}
+
+func TestCancelBookingHandler(t *testing.T) {
+	createTestClass(t, "Hiit", 5, 6, 1)
+
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Hiit"}`, futureDate(5))
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+
+	payload = fmt.Sprintf(`{"member_name":"John Smith", "class_date":"%s", "class_name": "Hiit", "waitlist": true}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusAccepted, response.Code)
+
+	var waiting structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &waiting)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	// The cancelled booking is kept with its status
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var cancelled structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &cancelled)
+	if cancelled.Status != structs.BookingStatusCancelled {
+		t.Errorf("Expected status %s, got %v", structs.BookingStatusCancelled, response.Body.String())
+	}
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/bookings/%d", booking.ID), nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	checkErrorCode(t, response, processors.ErrBookingCancelled.Code)
+
+	// The waitlisted member took the released spot
+	req, _ = http.NewRequest("GET", "/members/John%20Smith/bookings", nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var bookings []structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &bookings)
+	if len(bookings) != 1 || bookings[0].ID != waiting.ID || bookings[0].Status != structs.BookingStatusBooked {
+		t.Errorf("Expected booking %d of John Smith to be promoted, got %v", waiting.ID, response.Body.String())
+	}
+}
+
+func TestGetBookingsByDateHandler(t *testing.T) {
+	createTestClass(t, "Salsa", 70, 71, 10)
+
+	// A date without bookings answers 200 with an empty list, like GET /bookings
+	req, _ := http.NewRequest("GET", "/bookings/"+futureDate(70), nil)
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var page structs.BookingPage
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if page.Bookings == nil || len(page.Bookings) != 0 {
+		t.Errorf("Expected an empty list, got %v", response.Body.String())
 	}
+
+	// Cancelled bookings are left out
+	payload := fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%s", "class_name": "Salsa"}`, futureDate(70))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	var cancelled structs.Booking
+	json.Unmarshal(executeRequest(req).Body.Bytes(), &cancelled)
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/bookings/%d", cancelled.ID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+	payload = fmt.Sprintf(`{"member_name": "John", "class_date": "%s", "class_name": "Salsa"}`, futureDate(70))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	req, _ = http.NewRequest("GET", "/bookings/"+futureDate(70), nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	page = structs.BookingPage{}
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if len(page.Bookings) != 1 || page.Bookings[0].MemberName != "John" {
+		t.Errorf("Expected the booking of John, got %v", response.Body.String())
+	}
+}
+
+func TestBookClass_Duplicate(t *testing.T) {
+	createTestClass(t, "Aerobics", 5, 6, 10)
+
+	payload := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Aerobics"}`, futureDate(5))
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	payload = fmt.Sprintf(`{"member_name":" sai KUMAR ", "class_date":"%s", "class_name": "aerobics"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+
+	json.Unmarshal(response.Body.Bytes(), &errorResponse)
+	if !strings.Contains(errorResponse.Details, "member has already booked the class on the selected date") {
+		t.Errorf("Expected 'member has already booked the class on the selected date' error, got %v", errorResponse.Details)
+	}
+}
+
+// Run with go test -race to also check the bookings for data races
+func TestBookClass_Concurrent(t *testing.T) {
+	const requests, capacity = 300, 50
+	createTestClass(t, "Rowing", 5, 6, capacity)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payload := fmt.Sprintf(`{"member_name":"Member %d", "class_date":"%s", "class_name": "Rowing"}`, i, futureDate(5))
+			req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+			codes <- executeRequest(req).Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	booked := 0
+	for code := range codes {
+		if code == http.StatusOK {
+			booked++
+		} else {
+			checkResponseCode(t, http.StatusConflict, code)
+		}
+	}
+	if booked != capacity {
+		t.Errorf("Expected %d bookings to succeed, got %d", capacity, booked)
+	}
+
+	classDate, _ := time.Parse(DATEFORMAT, futureDate(5))
+	if bookings, _ := testStore.ListBookings("rowing", classDate); len(bookings) != capacity {
+		t.Errorf("Expected %d stored bookings, got %d", capacity, len(bookings))
+	}
+}
+
+func TestBookClass_Session(t *testing.T) {
+	payload := fmt.Sprintf(`{"class_name": "Aqua", "start_date": "%s", "end_date": "%s", "capacity": 10,
+		"schedule": {"start_times": ["07:00"], "duration": 45}}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req).Code)
+
+	payload = fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%sT07:00", "class_name": "Aqua"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.ClassDate.Format(SESSIONFORMAT) != futureDate(5)+"T07:00" {
+		t.Errorf("Expected booking for the 07:00 session, got %v", response.Body.String())
+	}
+
+	// Only the start of a session can be booked
+	for _, classDate := range []string{futureDate(5), futureDate(5) + "T08:00"} {
+		payload = fmt.Sprintf(`{"member_name": "John", "class_date": "%s", "class_name": "Aqua"}`, classDate)
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+	}
+
+	payload = fmt.Sprintf(`{"member_name": "John", "class_date": "%sT7am", "class_name": "Aqua"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req).Code)
+}
+
+func TestBookClass_StudioTimezone(t *testing.T) {
+	// The studio's date differs from the UTC date for most of the day in these zones
+	for _, timezone := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		handler := newTestHandler(storage.NewMemoryStore(), location)
+		now := time.Now().In(location)
+		today, yesterday := now.Format(DATEFORMAT), now.AddDate(0, 0, -1).Format(DATEFORMAT)
+
+		payload := fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 10}`, today, today)
+		req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusCreated, executeRequestWith(handler, req).Code)
+
+		payload = fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%s", "class_name": "Yoga"}`, today)
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+		response := executeRequestWith(handler, req)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		// Times are returned with the offset of the studio
+		offset := now.Format("-07:00")
+		if !strings.Contains(response.Body.String(), fmt.Sprintf(`"class_date":"%sT00:00:00%s"`, today, offset)) {
+			t.Errorf("Expected class_date with offset %s, got %v", offset, response.Body.String())
+		}
+
+		req, _ = http.NewRequest("GET", "/bookings/"+today, nil)
+		checkResponseCode(t, http.StatusOK, executeRequestWith(handler, req).Code)
+
+		payload = fmt.Sprintf(`{"member_name": "John", "class_date": "%s", "class_name": "Yoga"}`, yesterday)
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusBadRequest, executeRequestWith(handler, req).Code)
+	}
+}
+
+func TestGetBookingsHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.UTC)
+
+	// A query without bookings is an empty page
+	req, _ := http.NewRequest("GET", "/bookings", nil)
+	response := executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if strings.TrimSpace(response.Body.String()) != `{"bookings":[]}` {
+		t.Fatalf("expected an empty page, got %s", response.Body.String())
+	}
+
+	classBody := fmt.Sprintf(`{"class_name": "Rowing", "start_date": "%s", "end_date": "%s", "capacity": 10}`, futureDate(1), futureDate(3))
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBufferString(classBody))
+	checkResponseCode(t, http.StatusCreated, executeRequestWith(handler, req).Code)
+	for days := 1; days <= 3; days++ {
+		for _, member := range []string{"Sai Kumar", "John"} {
+			payload := fmt.Sprintf(`{"member_name": "%s", "class_name": "Rowing", "class_date": "%s"}`, member, futureDate(days))
+			req, _ = http.NewRequest("POST", "/bookings", bytes.NewBufferString(payload))
+			checkResponseCode(t, http.StatusOK, executeRequestWith(handler, req).Code)
+		}
+	}
+
+	// The pages of a filtered query hold every matching booking once
+	var bookings []structs.Booking
+	url := fmt.Sprintf("/bookings?from=%s&class_name=rowing&member=john&sort=-class_date&limit=1", futureDate(2))
+	for url != "" {
+		req, _ = http.NewRequest("GET", url, nil)
+		response = executeRequestWith(handler, req)
+		checkResponseCode(t, http.StatusOK, response.Code)
+
+		var page structs.BookingPage
+		json.Unmarshal(response.Body.Bytes(), &page)
+		bookings = append(bookings, page.Bookings...)
+		url = ""
+		if page.NextCursor != "" {
+			url = fmt.Sprintf("/bookings?from=%s&class_name=rowing&member=john&sort=-class_date&limit=1&cursor=%s", futureDate(2), page.NextCursor)
+		}
+	}
+	if len(bookings) != 2 || bookings[0].ClassDate.Format(DATEFORMAT) != futureDate(3) || bookings[1].MemberName != "John" {
+		t.Fatalf("expected the bookings of John from the latest date, got %v", bookings)
+	}
+}
+
+func TestGetBookingsHandler_InvalidQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/bookings?from=tomorrow&to=2025-01-01&sort=member&limit=500", nil)
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "from", "dateformat")
+	checkFieldError(t, response, "sort", "oneof")
+	checkFieldError(t, response, "limit", "max")
+
+	req, _ = http.NewRequest("GET", "/bookings?from=2025-02-01&to=2025-01-01", nil)
+	checkFieldError(t, executeRequest(req), "from", "ltefield")
+
+	req, _ = http.NewRequest("GET", "/bookings?cursor=bogus", nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkErrorCode(t, response, "invalid_cursor")
+}
+
+func TestMarkNoShowHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+	handler.bookings.SetRules(processors.BookingRules{MaxStrikes: 1})
+
+	// Today's all day session has started already
+	payload := fmt.Sprintf(`{"class_name": "Barre", "start_date": "%s", "end_date": "%s", "capacity": 5}`, futureDate(0), futureDate(1))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusCreated, executeRequestWith(handler, req).Code)
+	req, _ = http.NewRequest("POST", "/members", bytes.NewBuffer([]byte(`{"name": "Sai Kumar", "email": "sai@example.com"}`)))
+	checkResponseCode(t, http.StatusCreated, executeRequestWith(handler, req).Code)
+
+	payload = fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Barre"}`, futureDate(0))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/bookings/%d/no-show", booking.ID), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.Status != structs.BookingStatusNoShow {
+		t.Errorf("Expected status %s, got %v", structs.BookingStatusNoShow, response.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/bookings/%d/no-show", booking.ID), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	checkErrorCode(t, response, processors.ErrAttendanceRecorded.Code)
+
+	// The strike blocks the member from booking until it is reset
+	payload = fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Barre"}`, futureDate(1))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusForbidden, response.Code)
+	checkErrorCode(t, response, processors.ErrTooManyStrikes.Code)
+
+	// Sessions in the future cannot have no-shows yet
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(strings.Replace(payload, "Sai Kumar", "John", 1))))
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	json.Unmarshal(response.Body.Bytes(), &booking)
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/bookings/%d/no-show", booking.ID), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	checkErrorCode(t, response, processors.ErrSessionNotStarted.Code)
+}
+
+func TestCheckInHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+
+	// Today's all day session accepts check-ins until the end of the day
+	payload := fmt.Sprintf(`{"class_name": "Zumba", "start_date": "%s", "end_date": "%s", "capacity": 5}`, futureDate(0), futureDate(1))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+
+	var bookings []structs.Booking
+	for _, booking := range []struct{ member, date string }{{"Sai Kumar", futureDate(0)}, {"John", futureDate(0)}, {"Sai Kumar", futureDate(1)}} {
+		payload = fmt.Sprintf(`{"member_name":"%s", "class_date":"%s", "class_name": "Zumba"}`, booking.member, booking.date)
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+		response = executeRequestWith(handler, req)
+		checkResponseCode(t, http.StatusOK, response.Code)
+		var created structs.Booking
+		json.Unmarshal(response.Body.Bytes(), &created)
+		bookings = append(bookings, created)
+	}
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/bookings/%d/check-in", bookings[0].ID), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var checkedIn structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &checkedIn)
+	if checkedIn.Status != structs.BookingStatusAttended {
+		t.Errorf("Expected status %s, got %v", structs.BookingStatusAttended, response.Body.String())
+	}
+
+	// Tomorrow's session is not open for check-in yet
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/bookings/%d/check-in", bookings[2].ID), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	checkErrorCode(t, response, processors.ErrCheckInClosed.Code)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d/sessions/%s/roster", class.ID, futureDate(0)), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var roster structs.Roster
+	json.Unmarshal(response.Body.Bytes(), &roster)
+	if len(roster.Members) != 2 || !roster.Members[0].CheckedIn || roster.Members[1].CheckedIn || roster.Members[1].MemberName != "John" {
+		t.Errorf("Expected Sai Kumar checked in and John not, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d/sessions/%s/roster", class.ID, futureDate(5)), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequestWith(handler, req).Code)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d/sessions/tomorrow/roster", class.ID), nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequestWith(handler, req).Code)
 }
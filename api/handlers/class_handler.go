@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/saikumar-neelam/glofox_studio/internal/processors"
@@ -12,69 +14,285 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/go-playground/validator"
+	"github.com/gorilla/mux"
 )
 
-const DATEFORMAT = "2006-01-02"
+const (
+	DATEFORMAT    = "2006-01-02"
+	SESSIONFORMAT = "2006-01-02T15:04"
+)
 
 // CreateClassHandler handles the creation of a new class
-func CreateClassHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateClassHandler(w http.ResponseWriter, r *http.Request) {
 	var request structs.ClassRequest
 	// Decode the JSON body
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		SendErrorResponse(w, "Invalid request body", err.Error(), http.StatusBadRequest)
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
 		return
 	}
 
-	// Validate the request fields
-	err = validate.Struct(request)
+	// Weekdays are matched in lower case
+	if request.Schedule != nil {
+		for i, weekday := range request.Schedule.Weekdays {
+			request.Schedule.Weekdays[i] = strings.ToLower(strings.TrimSpace(weekday))
+		}
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
 	if err != nil {
-		// If validation fails, extract validation errors and return specific error messages
-		validationErrors := err.(validator.ValidationErrors)
-		for _, e := range validationErrors {
-			// Return a clear message indicating the missing field or invalid date format
-			errorMessage := fmt.Sprintf("%s is missing or invalid", e.Field())
-			SendErrorResponse(w, "Invalid Data", errorMessage, http.StatusBadRequest)
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	// Parse the start and end date, they are validated already
+	startDate, startErr := h.parseDate(request.StartDate)
+	endDate, endErr := h.parseDate(request.EndDate)
+
+	//check whether startdate/enddate is past date or not in the studio's timezone
+	if startErr == nil && startDate.Before(h.today()) {
+		fields.add("start_date", processors.ErrClassInPast.Code, "start_date cannot be a past date")
+	}
+	if endErr == nil && endDate.Before(h.today()) {
+		fields.add("end_date", processors.ErrClassInPast.Code, "end_date cannot be a past date")
+	}
+
+	//check whether startdate is before enddate or not
+	if startErr == nil && endErr == nil && startDate.After(endDate) {
+		fields.add("start_date", processors.ErrInvalidClassDates.Code, "start_date cannot be after end_date")
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	// Call the CreateClass processor to create class
+	newClass, err := h.classes.CreateClass(strings.ToLower(request.ClassName), startDate, endDate, request.Capacity, request.Schedule, request.CancellationPolicy, request.InstructorID, request.RoomID)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	h.metrics.classCreated(h.studioID)
+	utils.FromContext(r.Context()).Info("created class", "class_name", request.ClassName, "start_date", startDate, "end_date", endDate)
+
+	// Return the created class in the response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newClass)
+}
+
+// GetClassesHandler handles listing classes, optionally filtered by name and a from/to date range
+func (h *Handler) GetClassesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	var err error
+	if value := query.Get("from"); value != "" {
+		if from, err = h.parseDate(value); err != nil {
+			SendErrorResponse(w, r, fmt.Errorf("%w: from: %v", errInvalidDate, err))
+			return
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if to, err = h.parseDate(value); err != nil {
+			SendErrorResponse(w, r, fmt.Errorf("%w: to: %v", errInvalidDate, err))
 			return
 		}
 	}
 
-	// Parse the start and end date
-	startDate, err := time.Parse(DATEFORMAT, request.StartDate)
+	classes, err := h.classes.ListClasses(strings.ToLower(query.Get("name")), from, to)
 	if err != nil {
-		SendErrorResponse(w, "Invalid startDate format", err.Error(), http.StatusBadRequest)
+		SendErrorResponse(w, r, err)
 		return
 	}
-	endDate, err := time.Parse(DATEFORMAT, request.EndDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(classes)
+}
+
+// GetClassHandler handles fetching a single class by its id
+func (h *Handler) GetClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		SendErrorResponse(w, "Invalid endDate format", err.Error(), http.StatusBadRequest)
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
 		return
 	}
 
-	//check whether startdate/enddate is past date or not
-	if startDate.Before(time.Now()) || endDate.Before(time.Now()) {
-		SendErrorResponse(w, "Invalid startDate/endDate", "dates cannot be past date", http.StatusBadRequest)
+	class, err := h.classes.GetClass(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
 		return
 	}
 
-	//check whether startdate is before enddate or not
-	if startDate.After(endDate) {
-		SendErrorResponse(w, "Invalid startDate/endDate", "startDate cannot be greater than endDate", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(class)
+}
+
+// GetClassOccupancyHandler handles fetching the capacity, bookings, waitlist and remaining
+// spots of every session of a class, optionally limited to a from/to date range
+func (h *Handler) GetClassOccupancyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
 		return
 	}
 
-	// Call the CreateClass processor to create class
-	newClass, err := processors.CreateClass(strings.ToLower(request.ClassName), startDate, endDate, request.Capacity)
+	// Every invalid parameter is reported at once
+	query := r.URL.Query()
+	var fields fieldErrors
+	var from, to time.Time
+	if value := query.Get("from"); value != "" {
+		if from, err = h.parseDate(value); err != nil {
+			fields.add("from", "dateformat", "from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if to, err = h.parseDate(value); err != nil {
+			fields.add("to", "dateformat", "to must be a date in YYYY-MM-DD format")
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		fields.add("from", "ltefield", "from cannot be after to")
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	sessions, err := h.bookings.GetClassOccupancy(id, from, to)
 	if err != nil {
-		SendErrorResponse(w, "Invalid Data", err.Error(), http.StatusConflict)
+		SendErrorResponse(w, r, err)
 		return
 	}
 
-	utils.InfoLogger.Printf("Successfully created the class with classname %s from %s to %s", request.ClassName, startDate, endDate)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// GetOccupancyByDateHandler handles fetching the capacity, bookings, waitlist and remaining
+// spots of every class session on a date
+func (h *Handler) GetOccupancyByDateHandler(w http.ResponseWriter, r *http.Request) {
+	classDate, err := h.parseDate(mux.Vars(r)["classDate"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidDate, err))
+		return
+	}
+
+	sessions, err := h.bookings.GetOccupancyByDate(classDate)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
 
-	// Return the created class in the response
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(newClass)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// GetRosterHandler handles listing the booked members of a class session with their check-in
+// state, the session is a date for classes without schedule and a date and start time otherwise
+func (h *Handler) GetRosterHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	session, _, err := parseSession(vars["session"], h.location)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidDate, err))
+		return
+	}
+
+	roster, err := h.bookings.GetRoster(id, session)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roster)
+}
+
+// UpdateClassHandler handles changing the capacity or dates of a class
+func (h *Handler) UpdateClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	var request structs.UpdateClassRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	// Parse the dates which are being changed, they cannot be past dates
+	var startDate, endDate *time.Time
+	if request.StartDate != nil && !fields.has("start_date") {
+		date, _ := h.parseDate(*request.StartDate)
+		if date.Before(h.today()) {
+			fields.add("start_date", processors.ErrClassInPast.Code, "start_date cannot be a past date")
+		}
+		startDate = &date
+	}
+	if request.EndDate != nil && !fields.has("end_date") {
+		date, _ := h.parseDate(*request.EndDate)
+		if date.Before(h.today()) {
+			fields.add("end_date", processors.ErrClassInPast.Code, "end_date cannot be a past date")
+		}
+		endDate = &date
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	class, err := h.classes.UpdateClass(id, startDate, endDate, request.Capacity)
+	if errors.Is(err, processors.ErrWaitlistNotPromoted) {
+		// The change is stored, the spots left open can still be booked
+		utils.FromContext(r.Context()).Error("promoting waitlist", "class_id", class.ID, "error", err)
+	} else if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("updated class", "class_id", class.ID, "class_name", class.ClassName)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(class)
+}
+
+// DeleteClassHandler handles deleting a class which has no bookings
+func (h *Handler) DeleteClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	if err := h.classes.DeleteClass(id); err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("deleted class", "class_id", id)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -3,9 +3,16 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 )
 
 // Test for valid request
@@ -13,8 +20,8 @@ func TestCreateClassHandler_ValidRequest(t *testing.T) {
 
 	requestBody := map[string]interface{}{
 		"class_name": "Yoga",
-		"start_date": "2025-02-20",
-		"end_date":   "2025-03-15",
+		"start_date": futureDate(30),
+		"end_date":   futureDate(60),
 		"capacity":   10,
 	}
 	jsonBody, _ := json.Marshal(requestBody)
@@ -46,12 +53,29 @@ func TestCreateClassHandler_MissingFields(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
-	json.Unmarshal(response.Body.Bytes(), &errorResponse)
+	checkFieldError(t, response, "class_name", "required")
+
+}
 
-	if !strings.Contains(errorResponse.Details, "Name is missing or invalid") {
-		t.Errorf("Expected 'Name is missing or invalid' error, got %v", response.Body.String())
+// Test for a negative capacity
+func TestCreateClassHandler_NegativeCapacity(t *testing.T) {
+	requestBody := map[string]interface{}{
+		"class_name": "Yoga",
+		"start_date": futureDate(1),
+		"end_date":   futureDate(10),
+		"capacity":   -1,
 	}
+	jsonBody, _ := json.Marshal(requestBody)
+	req, err := http.NewRequest("POST", "/classes", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
 
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	checkFieldError(t, response, "capacity", "min")
 }
 
 // Test for invalid date format
@@ -72,11 +96,7 @@ func TestCreateClassHandler_InvalidDateFormat(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
-	json.Unmarshal(response.Body.Bytes(), &errorResponse)
-
-	if !strings.Contains(errorResponse.Details, "StartDate is missing or invalid") {
-		t.Errorf("Expected 'StartDate is missing or invalid' error, got %v", response.Body.String())
-	}
+	checkFieldError(t, response, "start_date", "dateformat")
 }
 
 // Test for CreateClass service error (e.g., class already exists)
@@ -84,8 +104,8 @@ func TestCreateClassHandler_InvalidDateFormat(t *testing.T) {
 func TestCreateClassHandler_CreateClassServiceError(t *testing.T) {
 	requestBody := map[string]interface{}{
 		"class_name": "Yoga",
-		"start_date": "2025-02-15",
-		"end_date":   "2025-03-15",
+		"start_date": futureDate(25),
+		"end_date":   futureDate(60),
 		"capacity":   10,
 	}
 	jsonBody, _ := json.Marshal(requestBody)
@@ -115,12 +135,12 @@ func TestCreateClassHandler_InvalidJSON(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
-	expected := "Invalid request body"
+	expected := "invalid_body"
 
 	json.Unmarshal(response.Body.Bytes(), &errorResponse)
 
-	if !strings.Contains(errorResponse.Error, expected) {
-		t.Errorf("Expected body %v, but got %v", expected, errorResponse.Details)
+	if errorResponse.Code != expected {
+		t.Errorf("Expected code %v, but got %v", expected, response.Body.String())
 	}
 }
 
@@ -128,8 +148,8 @@ func TestCreateClassHandler_InvalidJSON(t *testing.T) {
 func TestCreateClassHandler_InvalidStartDate(t *testing.T) {
 	requestBody := map[string]interface{}{
 		"class_name": "Pilates",
-		"start_date": "2025-02-25",
-		"end_date":   "2025-02-20",
+		"start_date": futureDate(40),
+		"end_date":   futureDate(35),
 		"capacity":   10,
 	}
 	jsonBody, _ := json.Marshal(requestBody)
@@ -142,9 +162,310 @@ func TestCreateClassHandler_InvalidStartDate(t *testing.T) {
 	response := executeRequest(req)
 	checkResponseCode(t, http.StatusBadRequest, response.Code)
 
+	checkFieldError(t, response, "start_date", "invalid_class_dates")
+}
+
+// createTestClass creates a class through the API and returns it
+func createTestClass(t *testing.T, name string, startDays, endDays, capacity int) structs.Class {
+	t.Helper()
+
+	payload := fmt.Sprintf(`{"class_name": "%s", "start_date": "%s", "end_date": "%s", "capacity": %d}`, name, futureDate(startDays), futureDate(endDays), capacity)
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+	return class
+}
+
+func TestCreateClassHandler_Schedule(t *testing.T) {
+	payload := fmt.Sprintf(`{"class_name": "Tabata", "start_date": "%s", "end_date": "%s", "capacity": 10,
+		"schedule": {"weekdays": ["MON", "Tue", "wed", "thu", "fri", "sat", "sun"], "start_times": ["07:00", "18:30"], "duration": 45}}`, futureDate(5), futureDate(12))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+	if class.Schedule == nil || class.Schedule.Weekdays[0] != "mon" || class.Schedule.Duration != 45 {
+		t.Errorf("Expected the schedule in the response, got %v", response.Body.String())
+	}
+
+	// An other tabata class overlapping the evening sessions is a conflict
+	payload = fmt.Sprintf(`{"class_name": "Tabata", "start_date": "%s", "end_date": "%s", "capacity": 10,
+		"schedule": {"start_times": ["19:00"], "duration": 30}}`, futureDate(10), futureDate(20))
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusConflict, executeRequest(req).Code)
+
+	for _, schedule := range []string{
+		`{"start_times": ["7pm"], "duration": 30}`,
+		`{"start_times": ["07:00"], "duration": 0}`,
+		`{"weekdays": ["someday"], "start_times": ["07:00"], "duration": 30}`,
+		`{"start_times": ["07:00"], "duration": 30, "rrule": "FREQ=SOMETIMES"}`,
+	} {
+		payload = fmt.Sprintf(`{"class_name": "Tabata", "start_date": "%s", "end_date": "%s", "capacity": 10, "schedule": %s}`, futureDate(30), futureDate(40), schedule)
+		req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req).Code)
+	}
+}
+
+func TestGetClassesHandler_Filters(t *testing.T) {
+	createTestClass(t, "Boxing", 5, 10, 10)
+	createTestClass(t, "Boxing", 20, 25, 10)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/classes?name=boxing&from=%s&to=%s", futureDate(15), futureDate(30)), nil)
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var classes []structs.Class
+	json.Unmarshal(response.Body.Bytes(), &classes)
+	if len(classes) != 1 || classes[0].StartDate.Format(DATEFORMAT) != futureDate(20) {
+		t.Errorf("Expected only the second boxing class, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/classes?from=2025-13-01", nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req).Code)
+}
+
+func TestGetClassHandler_NotFound(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/classes/99999", nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+
+	req, _ = http.NewRequest("GET", "/classes/abc", nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req).Code)
+}
+
+func TestUpdateClassHandler(t *testing.T) {
+	class := createTestClass(t, "Crossfit", 5, 6, 2)
+
+	payload := fmt.Sprintf(`{"end_date": "%s", "capacity": 5}`, futureDate(10))
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf("/classes/%d", class.ID), bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var updated structs.Class
+	json.Unmarshal(response.Body.Bytes(), &updated)
+	if updated.Capacity != 5 || updated.EndDate.Format(DATEFORMAT) != futureDate(10) {
+		t.Errorf("Expected capacity 5 until %s, got %v", futureDate(10), response.Body.String())
+	}
+
+	// Book both days so the schedule cannot shrink anymore
+	for _, days := range []int{5, 10} {
+		booking := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Crossfit"}`, futureDate(days))
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(booking)))
+		checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+	}
+
+	payload = fmt.Sprintf(`{"end_date": "%s"}`, futureDate(8))
+	req, _ = http.NewRequest("PATCH", fmt.Sprintf("/classes/%d", class.ID), bytes.NewBuffer([]byte(payload)))
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+
 	json.Unmarshal(response.Body.Bytes(), &errorResponse)
+	if !strings.Contains(errorResponse.Details, "class has bookings on dates outside the new schedule") {
+		t.Errorf("Expected 'class has bookings on dates outside the new schedule' error, got %v", errorResponse.Details)
+	}
+}
+
+// failingChangeStore fails every booking change while fail is set
+type failingChangeStore struct {
+	*storage.MemoryStore
+	fail bool
+}
+
+func (s *failingChangeStore) ApplyChange(change storage.BookingChange) (structs.Booking, error) {
+	if s.fail {
+		return structs.Booking{}, errors.New("disk full")
+	}
+	return s.MemoryStore.ApplyChange(change)
+}
+
+func TestUpdateClassHandler_PromotionFails(t *testing.T) {
+	store := &failingChangeStore{MemoryStore: storage.NewMemoryStore()}
+	handler := newTestHandler(store, time.Local)
+	response := executeJSON(handler, "POST", "/classes",
+		fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(5)))
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+	for _, member := range []string{"Sai Kumar", "John"} {
+		executeJSON(handler, "POST", "/bookings",
+			fmt.Sprintf(`{"member_name": "%s", "class_date": "%s", "class_name": "Yoga", "waitlist": true}`, member, futureDate(5)))
+	}
 
-	if !strings.Contains(errorResponse.Details, "startDate cannot be greater than endDate") {
-		t.Errorf("Expected 'startDate cannot be greater than endDate' error, got %v", errorResponse.Details)
+	// The capacity change is answered as stored although the waitlist could not be promoted
+	store.fail = true
+	response = executeJSON(handler, "PATCH", fmt.Sprintf("/classes/%d", class.ID), `{"capacity": 2}`)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var updated structs.Class
+	json.Unmarshal(response.Body.Bytes(), &updated)
+	if updated.Capacity != 2 {
+		t.Errorf("Expected capacity 2, got %v", response.Body.String())
 	}
 }
+
+func TestDeleteClassHandler(t *testing.T) {
+	class := createTestClass(t, "Stretching", 5, 6, 2)
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/classes/%d", class.ID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req).Code)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d", class.ID), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+
+	// Classes with bookings are kept
+	class = createTestClass(t, "Stretching", 5, 6, 2)
+	booking := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Stretching"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(booking)))
+	checkResponseCode(t, http.StatusOK, executeRequest(req).Code)
+
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/classes/%d", class.ID), nil)
+	checkResponseCode(t, http.StatusConflict, executeRequest(req).Code)
+}
+
+// Run with go test -race to also check the class registry for data races
+func TestCreateClassHandler_Concurrent(t *testing.T) {
+	const requests = 200
+
+	var wg sync.WaitGroup
+	codes := make(chan int, requests)
+	ids := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(2)
+
+		// Every request tries to schedule the same class on the same dates
+		go func() {
+			defer wg.Done()
+			payload := fmt.Sprintf(`{"class_name": "Spinning", "start_date": "%s", "end_date": "%s", "capacity": 10}`, futureDate(5), futureDate(10))
+			req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+			codes <- executeRequest(req).Code
+		}()
+
+		// Classes with different names never conflict and each gets an own id
+		go func(i int) {
+			defer wg.Done()
+			payload := fmt.Sprintf(`{"class_name": "Concurrent %d", "start_date": "%s", "end_date": "%s", "capacity": 10}`, i, futureDate(5), futureDate(10))
+			req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+			response := executeRequest(req)
+
+			var class structs.Class
+			json.Unmarshal(response.Body.Bytes(), &class)
+			ids <- class.ID
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+	close(ids)
+
+	created := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			created++
+		} else {
+			checkResponseCode(t, http.StatusConflict, code)
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected exactly one Spinning class to be created, got %d", created)
+	}
+
+	seen := make(map[int]bool)
+	for id := range ids {
+		if id == 0 || seen[id] {
+			t.Fatalf("Expected unique class ids, got %d twice or missing", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestGetClassOccupancyHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+
+	payload := fmt.Sprintf(`{"class_name": "Pilates", "start_date": "%s", "end_date": "%s", "capacity": 2}`, futureDate(5), futureDate(7))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+
+	booking := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Pilates"}`, futureDate(6))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(booking)))
+	checkResponseCode(t, http.StatusOK, executeRequestWith(handler, req).Code)
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d/occupancy?from=%s", class.ID, futureDate(6)), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var sessions []structs.Occupancy
+	json.Unmarshal(response.Body.Bytes(), &sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected the sessions from %s, got %v", futureDate(6), response.Body.String())
+	}
+	if sessions[0].ClassDate.Format(DATEFORMAT) != futureDate(6) || sessions[0].Booked != 1 || sessions[0].Remaining != 1 {
+		t.Errorf("Expected 1 booked and 1 remaining on %s, got %v", futureDate(6), sessions[0])
+	}
+	if sessions[1].Booked != 0 || sessions[1].Remaining != 2 {
+		t.Errorf("Expected 2 remaining on %s, got %v", futureDate(7), sessions[1])
+	}
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/classes/%d/occupancy?from=%s&to=%s", class.ID, futureDate(7), futureDate(6)), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "from", "ltefield")
+
+	req, _ = http.NewRequest("GET", "/classes/99/occupancy", nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequestWith(handler, req).Code)
+}
+
+func TestGetOccupancyByDateHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+
+	for _, name := range []string{"Yoga", "Spin"} {
+		payload := fmt.Sprintf(`{"class_name": "%s", "start_date": "%s", "end_date": "%s", "capacity": 3}`, name, futureDate(5), futureDate(5))
+		req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusCreated, executeRequestWith(handler, req).Code)
+	}
+	booking := fmt.Sprintf(`{"member_name":"Sai Kumar", "class_date":"%s", "class_name": "Yoga"}`, futureDate(5))
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(booking)))
+	checkResponseCode(t, http.StatusOK, executeRequestWith(handler, req).Code)
+
+	req, _ = http.NewRequest("GET", "/occupancy/"+futureDate(5), nil)
+	response := executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var sessions []structs.Occupancy
+	json.Unmarshal(response.Body.Bytes(), &sessions)
+	if len(sessions) != 2 || sessions[0].ClassName != "spin" || sessions[1].ClassName != "yoga" || sessions[1].Booked != 1 || sessions[1].Remaining != 2 {
+		t.Errorf("Expected the spin and yoga sessions, got %v", response.Body.String())
+	}
+
+	// Dates without sessions return an empty list
+	req, _ = http.NewRequest("GET", "/occupancy/"+futureDate(6), nil)
+	response = executeRequestWith(handler, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if strings.TrimSpace(response.Body.String()) != "[]" {
+		t.Errorf("Expected an empty list, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/occupancy/2025-13-01", nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequestWith(handler, req).Code)
+}
+
+func TestCreateClassHandler_CancellationPolicy(t *testing.T) {
+	payload := fmt.Sprintf(`{"class_name": "Bodypump", "start_date": "%s", "end_date": "%s", "capacity": 5, "cancellation_policy": {"late_cancel_hours": 12}}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+	if class.CancellationPolicy == nil || class.CancellationPolicy.LateCancelHours != 12 {
+		t.Errorf("Expected a late cancel window of 12 hours, got %v", response.Body.String())
+	}
+
+	payload = strings.Replace(payload, `"late_cancel_hours": 12`, `"late_cancel_hours": -1`, 1)
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "cancellation_policy.late_cancel_hours", "min")
+}
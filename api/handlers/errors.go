@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+)
+
+// problemContentType is the RFC 7807 media type, clients opt in to problem responses by accepting it
+const problemContentType = "application/problem+json"
+
+// Errors of the API itself, the domain errors are defined by the processors
+var (
+	errInvalidBody        = &processors.Error{Code: "invalid_body", Kind: processors.KindInvalid, Message: "request body is not valid JSON"}
+	errInvalidRequest     = &processors.Error{Code: "invalid_request", Kind: processors.KindInvalid, Message: "request has invalid fields"}
+	errInvalidID          = &processors.Error{Code: "invalid_id", Kind: processors.KindInvalid, Message: "id must be a number"}
+	errInvalidDate        = &processors.Error{Code: "invalid_date", Kind: processors.KindInvalid, Message: "date must be in YYYY-MM-DD format"}
+	errForbidden          = &processors.Error{Code: "forbidden", Kind: processors.KindForbidden, Message: "the credentials do not allow this request"}
+	errNotOwnResource     = &processors.Error{Code: "not_own_resource", Kind: processors.KindForbidden, Message: "members can only act for themselves"}
+	errUnregisteredMember = &processors.Error{Code: "member_not_registered", Kind: processors.KindForbidden, Message: "the member of the credentials is not registered"}
+	errNotReady           = &processors.Error{Code: "not_ready", Kind: processors.KindUnavailable, Message: "the service cannot serve requests"}
+)
+
+// errInternal is the code of errors which are not domain errors
+const errInternal = "internal_error"
+
+// kindStatus is the HTTP status of each kind of domain error
+var kindStatus = map[processors.Kind]int{
+	processors.KindInternal:     http.StatusInternalServerError,
+	processors.KindInvalid:      http.StatusBadRequest,
+	processors.KindUnauthorized: http.StatusUnauthorized,
+	processors.KindForbidden:    http.StatusForbidden,
+	processors.KindNotFound:     http.StatusNotFound,
+	processors.KindConflict:     http.StatusConflict,
+	processors.KindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// kindTitle is the error title of each kind of domain error
+var kindTitle = map[processors.Kind]string{
+	processors.KindInternal:     "Unable to Process Request",
+	processors.KindInvalid:      "Invalid Data",
+	processors.KindUnauthorized: "Unauthorized",
+	processors.KindForbidden:    "Forbidden",
+	processors.KindNotFound:     "Not Found",
+	processors.KindConflict:     "Conflict",
+	processors.KindUnavailable:  "Service Unavailable",
+}
+
+// SendErrorResponse responds with the status and code of the domain error, errors which are not
+// domain errors are internal errors. The response is an RFC 7807 problem when the request accepts it.
+func SendErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	sendError(w, r, err, nil)
+}
+
+// sendError responds with the error and the invalid fields of the request
+func sendError(w http.ResponseWriter, r *http.Request, err error, fields []structs.FieldError) {
+	code, kind := errorCode(err), errorKind(err)
+	status := kindStatus[kind]
+
+	if kind == processors.KindUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="glofox"`)
+	}
+
+	errorResponse := structs.ErrorResponse{
+		Error:   kindTitle[kind],
+		Code:    code,
+		Details: err.Error(),
+		Status:  status,
+		Fields:  fields,
+	}
+	logger := utils.FromContext(r.Context())
+	if kind == processors.KindInternal {
+		logger.Error("request failed", "status", status, "code", code, "error", err)
+	} else {
+		logger.Info("request refused", "status", status, "code", code, "error", err)
+	}
+
+	// Encode the error response as JSON and write it to the response writer
+	if acceptsProblem(r) {
+		w.Header().Set("Content-Type", problemContentType)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(structs.ProblemDetails{
+			Type:     "urn:glofox:problem:" + code,
+			Title:    errorResponse.Error,
+			Status:   status,
+			Detail:   errorResponse.Details,
+			Instance: r.URL.Path,
+			Code:     code,
+			Fields:   fields,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse)
+}
+
+// errorCode returns the code of the domain error, errors which are not domain errors are internal errors
+func errorCode(err error) string {
+	var domainErr *processors.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return errInternal
+}
+
+// errorKind returns the kind of the domain error, errors which are not domain errors are internal errors
+func errorKind(err error) processors.Kind {
+	var domainErr *processors.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return processors.KindInternal
+}
+
+// acceptsProblem reports whether the client asked for RFC 7807 problem responses
+func acceptsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), problemContentType) {
+				return true
+			}
+		}
+	}
+	return false
+}
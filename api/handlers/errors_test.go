@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestSendErrorResponse_Status(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{processors.ErrClassFull, http.StatusConflict, "class_full"},
+		{fmt.Errorf("%w: unknown weekday", processors.ErrInvalidSchedule), http.StatusBadRequest, "invalid_schedule"},
+		{processors.ErrClassNotFound, http.StatusNotFound, "class_not_found"},
+		{auth.ErrMissingCredentials, http.StatusUnauthorized, "missing_credentials"},
+		{errNotOwnResource, http.StatusForbidden, "not_own_resource"},
+		{errors.New("disk is full"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/classes", nil)
+			rr := httptest.NewRecorder()
+			SendErrorResponse(rr, req, tt.err)
+			checkResponseCode(t, tt.status, rr.Code)
+
+			var body structs.ErrorResponse
+			json.Unmarshal(rr.Body.Bytes(), &body)
+			if body.Code != tt.code || body.Status != tt.status || body.Details != tt.err.Error() {
+				t.Errorf("Expected code %s, got %v", tt.code, rr.Body.String())
+			}
+			if got := rr.Header().Get("WWW-Authenticate"); (got != "") != (tt.status == http.StatusUnauthorized) {
+				t.Errorf("Unexpected WWW-Authenticate header %q", got)
+			}
+		})
+	}
+}
+
+func TestSendErrorResponse_Problem(t *testing.T) {
+	payload := fmt.Sprintf(`{"class_name": "", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	req.Header.Set("Accept", "application/json;q=0.9, application/problem+json")
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	if contentType := response.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Errorf("Expected a problem response, got %s", contentType)
+	}
+	var problem structs.ProblemDetails
+	json.Unmarshal(response.Body.Bytes(), &problem)
+	if problem.Type != "urn:glofox:problem:invalid_request" || problem.Code != "invalid_request" || problem.Instance != "/classes" ||
+		problem.Status != http.StatusBadRequest || len(problem.Fields) != 1 {
+		t.Errorf("Expected an invalid_request problem for /classes, got %v", response.Body.String())
+	}
+
+	// Clients which do not ask for problems get the error response
+	req, _ = http.NewRequest("GET", "/classes/999", nil)
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+	if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON response, got %s", contentType)
+	}
+}
+
+func TestBookClass_ErrorCodes(t *testing.T) {
+	classBody := fmt.Sprintf(`{"class_name": "Aerial", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(6))
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req).Code)
+
+	// The same class again clashes with the first one
+	req, _ = http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(classBody)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	checkErrorCode(t, response, "class_conflict")
+
+	tests := []struct {
+		payload string
+		status  int
+		code    string
+	}{
+		{fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%s", "class_name": "Aerial"}`, futureDate(5)), http.StatusOK, ""},
+		{fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%s", "class_name": "Aerial"}`, futureDate(5)), http.StatusConflict, "already_booked"},
+		{fmt.Sprintf(`{"member_name": "John", "class_date": "%s", "class_name": "Aerial"}`, futureDate(5)), http.StatusConflict, "class_full"},
+		{fmt.Sprintf(`{"member_name": "John", "class_date": "%s", "class_name": "Aerial"}`, futureDate(9)), http.StatusNotFound, "class_not_scheduled"},
+		{fmt.Sprintf(`{"member_id": 999, "class_date": "%s", "class_name": "Aerial"}`, futureDate(6)), http.StatusNotFound, "member_not_found"},
+	}
+	for _, tt := range tests {
+		req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(tt.payload)))
+		response = executeRequest(req)
+		checkResponseCode(t, tt.status, response.Code)
+		if tt.code != "" {
+			checkErrorCode(t, response, tt.code)
+		}
+	}
+}
+
+// checkErrorCode checks the code of the error response
+func checkErrorCode(t *testing.T, response *httptest.ResponseRecorder, code string) {
+	t.Helper()
+
+	var body structs.ErrorResponse
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if body.Code != code {
+		t.Errorf("Expected code %s, got %v", code, response.Body.String())
+	}
+}
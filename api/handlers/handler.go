@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+)
+
+// Handler serves the HTTP API on top of the class, booking and member processors.
+// Dates in requests are read in the studio's timezone and "today" is the studio's date.
+type Handler struct {
+	classes  *processors.ClassProcessor
+	bookings *processors.BookingProcessor
+	members  *processors.MemberProcessor
+	location *time.Location
+
+	studioID int      //studio the request is served for, metrics are labelled with it
+	metrics  *Metrics //nil when metrics are not collected
+}
+
+// NewHandler creates a handler which delegates to the given processors, location is the
+// IANA timezone of the studio
+func NewHandler(classes *processors.ClassProcessor, bookings *processors.BookingProcessor, members *processors.MemberProcessor, location *time.Location) *Handler {
+	return &Handler{classes: classes, bookings: bookings, members: members, location: location}
+}
+
+// parseDate parses a YYYY-MM-DD date as midnight in the studio's timezone
+func (h *Handler) parseDate(value string) (time.Time, error) {
+	return time.ParseInLocation(DATEFORMAT, value, h.location)
+}
+
+// today returns midnight of the current date in the studio's timezone
+func (h *Handler) today() time.Time {
+	return today(h.location)
+}
+
+// today returns midnight of the current date in the location
+func today(location *time.Location) time.Time {
+	now := time.Now().In(location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+}
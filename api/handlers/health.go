@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/version"
+)
+
+// readinessTimeout bounds the readiness check, load balancers give up on slow probes anyway
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler serves the liveness, readiness and build info probes of the service
+type HealthHandler struct {
+	ready func(context.Context) error
+}
+
+// NewHealthHandler creates a handler whose readiness is reported by ready, e.g. the Check of the store
+func NewHealthHandler(ready func(context.Context) error) *HealthHandler {
+	return &HealthHandler{ready: ready}
+}
+
+// LivenessHandler reports that the process serves requests
+func (h *HealthHandler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	sendStatus(w, "ok")
+}
+
+// ReadinessHandler reports whether the storage backend can serve requests
+func (h *HealthHandler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := h.ready(ctx); err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errNotReady, err))
+		return
+	}
+	sendStatus(w, "ready")
+}
+
+// VersionHandler returns the version, git commit and build time of the running build
+func (h *HealthHandler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(version.Get())
+}
+
+// sendStatus responds with the status of a probe
+func sendStatus(w http.ResponseWriter, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
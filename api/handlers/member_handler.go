@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateMemberHandler handles registering a new member
+func (h *Handler) CreateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	var request structs.MemberRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	member, err := h.members.CreateMember(request.Name, request.Email, request.Phone)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("registered member", "member_id", member.ID, "member_name", member.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+// GetMemberHandler handles fetching a member by its id
+func (h *Handler) GetMemberHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	// Members can only see and change their own details
+	if !h.checkActingMember(w, r, func(member structs.Member) bool { return member.ID == id }) {
+		return
+	}
+
+	member, err := h.members.GetMember(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// UpdateMemberHandler handles changing the contact details of a member
+func (h *Handler) UpdateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	// Members can only see and change their own details
+	if !h.checkActingMember(w, r, func(member structs.Member) bool { return member.ID == id }) {
+		return
+	}
+
+	var request structs.UpdateMemberRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	member, err := h.members.UpdateMember(id, request.Email, request.Phone)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("updated member", "member_id", member.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// ResetStrikesHandler handles clearing the late cancellations and no-shows of a member,
+// so a blocked member can book again
+func (h *Handler) ResetStrikesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	member, err := h.members.ResetStrikes(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("reset member strikes", "member_id", member.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// AssignMembershipHandler handles giving a member a plan, which replaces the current one
+func (h *Handler) AssignMembershipHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	var request structs.MembershipRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if request.Plan != structs.PlanUnlimited && request.Credits == 0 && !fields.has("credits") {
+		fields.add("credits", "required", "credits is required for monthly plans and packs")
+	}
+	if request.Plan == structs.PlanPack && request.ExpiresOn == "" {
+		fields.add("expires_on", "required", "expires_on is required for packs")
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	membership := structs.Membership{Plan: request.Plan, Credits: request.Credits}
+	if request.ExpiresOn != "" {
+		expiresOn, err := h.parseDate(request.ExpiresOn)
+		if err != nil {
+			SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidDate, err))
+			return
+		}
+		membership.ExpiresOn = &expiresOn
+	}
+
+	member, err := h.members.AssignMembership(id, membership)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("assigned membership", "member_id", member.ID, "plan", membership.Plan)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// GetBalanceHandler handles looking up what a member can still book with their membership,
+// monthly credits are those of the current month
+func (h *Handler) GetBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	// Members can only see their own balance
+	if !h.checkActingMember(w, r, func(member structs.Member) bool { return member.ID == id }) {
+		return
+	}
+
+	balance, err := h.members.GetBalance(id, h.today())
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(balance)
+}
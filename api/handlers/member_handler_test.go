@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// createTestMember registers a member through the API and returns it
+func createTestMember(t *testing.T, name string) structs.Member {
+	t.Helper()
+
+	payload := fmt.Sprintf(`{"name": "%s", "email": "member@example.com", "phone": "+14155552671"}`, name)
+	req, _ := http.NewRequest("POST", "/members", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var member structs.Member
+	json.Unmarshal(response.Body.Bytes(), &member)
+	return member
+}
+
+func TestCreateMemberHandler(t *testing.T) {
+	member := createTestMember(t, "Priya Sharma")
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/members/%d", member.ID), nil)
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var found structs.Member
+	json.Unmarshal(response.Body.Bytes(), &found)
+	if found.Name != "Priya Sharma" || found.Email != "member@example.com" {
+		t.Errorf("Expected member Priya Sharma, got %v", response.Body.String())
+	}
+
+	// Names are unique after normalization
+	req, _ = http.NewRequest("POST", "/members", bytes.NewBuffer([]byte(`{"name": " priya SHARMA", "email": "other@example.com"}`)))
+	checkResponseCode(t, http.StatusConflict, executeRequest(req).Code)
+}
+
+func TestCreateMemberHandler_InvalidEmail(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/members", bytes.NewBuffer([]byte(`{"name": "Ravi", "email": "not-an-email"}`)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	checkFieldError(t, response, "email", "email")
+}
+
+func TestUpdateMemberHandler(t *testing.T) {
+	member := createTestMember(t, "Anil Rao")
+
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf("/members/%d", member.ID), bytes.NewBuffer([]byte(`{"email": "anil@example.com"}`)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var updated structs.Member
+	json.Unmarshal(response.Body.Bytes(), &updated)
+	if updated.Email != "anil@example.com" || updated.Phone != member.Phone {
+		t.Errorf("Expected only the email to change, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("PATCH", "/members/99999", bytes.NewBuffer([]byte(`{"email": "anil@example.com"}`)))
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+}
+
+func TestBookClass_ByMemberID(t *testing.T) {
+	createTestClass(t, "Kettlebell", 5, 6, 10)
+	member := createTestMember(t, "Meera Iyer")
+
+	payload := fmt.Sprintf(`{"member_id": %d, "class_date": "%s", "class_name": "Kettlebell"}`, member.ID, futureDate(5))
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if booking.MemberID != member.ID || booking.MemberName != "Meera Iyer" {
+		t.Errorf("Expected booking of member %d, got %v", member.ID, response.Body.String())
+	}
+	if response.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected no Deprecation header for bookings by member_id")
+	}
+
+	payload = fmt.Sprintf(`{"member_id": 99999, "class_date": "%s", "class_name": "Kettlebell"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+
+	// Booking by name still works but is deprecated
+	payload = fmt.Sprintf(`{"member_name": "Guest", "class_date": "%s", "class_name": "Kettlebell"}`, futureDate(5))
+	req, _ = http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(payload)))
+	response = executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if response.Header().Get("Deprecation") != "true" {
+		t.Errorf("Expected Deprecation header for bookings by member_name")
+	}
+}
+
+func TestResetStrikesHandler(t *testing.T) {
+	member := createTestMember(t, "Kavya Nair")
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/members/%d/strikes", member.ID), nil)
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var reset structs.Member
+	json.Unmarshal(response.Body.Bytes(), &reset)
+	if reset.ID != member.ID || reset.Strikes != 0 {
+		t.Errorf("Expected member %d without strikes, got %v", member.ID, response.Body.String())
+	}
+
+	req, _ = http.NewRequest("DELETE", "/members/99999/strikes", nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req).Code)
+}
+
+func TestAssignMembershipHandler(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+	checkResponseCode(t, http.StatusCreated, executeJSON(handler, "POST", "/classes",
+		fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 10}`, futureDate(5), futureDate(6))).Code)
+	response := executeJSON(handler, "POST", "/members", `{"name": "Rohan Das", "email": "rohan@example.com"}`)
+	var member structs.Member
+	json.Unmarshal(response.Body.Bytes(), &member)
+
+	// Packs need credits and an expiry date
+	response = executeJSON(handler, "PUT", fmt.Sprintf("/members/%d/membership", member.ID), `{"plan": "pack"}`)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "credits", "required")
+	checkFieldError(t, response, "expires_on", "required")
+	checkResponseCode(t, http.StatusNotFound, executeJSON(handler, "GET", fmt.Sprintf("/members/%d/balance", member.ID), "").Code)
+
+	response = executeJSON(handler, "PUT", fmt.Sprintf("/members/%d/membership", member.ID), fmt.Sprintf(`{"plan": "pack", "credits": 1, "expires_on": "%s"}`, futureDate(30)))
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	// The booking uses the only credit of the pack
+	response = executeJSON(handler, "POST", "/bookings", fmt.Sprintf(`{"member_id": %d, "class_date": "%s", "class_name": "Yoga"}`, member.ID, futureDate(5)))
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var booking structs.Booking
+	json.Unmarshal(response.Body.Bytes(), &booking)
+	if !booking.CreditUsed {
+		t.Errorf("Expected the booking to use a credit, got %v", response.Body.String())
+	}
+
+	response = executeJSON(handler, "POST", "/bookings", fmt.Sprintf(`{"member_id": %d, "class_date": "%s", "class_name": "Yoga"}`, member.ID, futureDate(6)))
+	checkResponseCode(t, http.StatusForbidden, response.Code)
+	var body structs.ErrorResponse
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if body.Code != "no_credit" {
+		t.Errorf("Expected code no_credit, got %v", response.Body.String())
+	}
+
+	response = executeJSON(handler, "GET", fmt.Sprintf("/members/%d/balance", member.ID), "")
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var balance structs.Balance
+	json.Unmarshal(response.Body.Bytes(), &balance)
+	if balance.Plan != structs.PlanPack || balance.Remaining == nil || *balance.Remaining != 0 {
+		t.Errorf("Expected a pack without credits left, got %v", response.Body.String())
+	}
+
+	checkResponseCode(t, http.StatusNotFound, executeJSON(handler, "PUT", "/members/99999/membership", `{"plan": "unlimited"}`).Code)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/metrics"
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"github.com/gorilla/mux"
+)
+
+// Metrics counts the HTTP traffic, bookings and classes of the API and serves them with the
+// occupancy of the upcoming class sessions in the Prometheus text format
+type Metrics struct {
+	registry         *metrics.Registry
+	requests         *metrics.CounterVec
+	latency          *metrics.HistogramVec
+	bookingsCreated  *metrics.CounterVec
+	bookingsRejected *metrics.CounterVec
+	classesCreated   *metrics.CounterVec
+}
+
+// NewMetrics creates the metrics of the API, the occupancy is read from the studios when the
+// metrics are served
+func NewMetrics(studios *processors.StudioProcessor) *Metrics {
+	registry := metrics.NewRegistry()
+	m := &Metrics{
+		registry: registry,
+		requests: registry.NewCounterVec("glofox_http_requests_total",
+			"HTTP requests served by route, method and status code.", "route", "method", "status"),
+		latency: registry.NewHistogramVec("glofox_http_request_duration_seconds",
+			"Time taken to serve HTTP requests by route and method.", metrics.DefaultBuckets, "route", "method"),
+		bookingsCreated: registry.NewCounterVec("glofox_bookings_created_total",
+			"Bookings created by studio and booking status.", "studio", "status"),
+		bookingsRejected: registry.NewCounterVec("glofox_bookings_rejected_total",
+			"Booking requests rejected by studio and error code.", "studio", "reason"),
+		classesCreated: registry.NewCounterVec("glofox_classes_created_total",
+			"Classes created by studio.", "studio"),
+	}
+	registry.NewGaugeFunc("glofox_class_session_bookings",
+		"Confirmed bookings of upcoming class sessions which have bookings.",
+		func() ([]metrics.Sample, error) { return occupancySamples(studios, false) },
+		"studio", "class_name", "date")
+	registry.NewGaugeFunc("glofox_class_session_capacity",
+		"Capacity of upcoming class sessions which have bookings.",
+		func() ([]metrics.Sample, error) { return occupancySamples(studios, true) },
+		"studio", "class_name", "date")
+	return m
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format
+func (m *Metrics) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	m.registry.Handler()(w, r)
+}
+
+// Middleware counts the requests and their latency by the path template of their route
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+
+		m.requests.Inc(route, r.Method, strconv.Itoa(recorder.status))
+		m.latency.Observe(time.Since(start).Seconds(), route, r.Method)
+	})
+}
+
+// Instrument counts every request served by the router, requests which match no route or
+// method are counted under the unmatched route
+func (m *Metrics) Instrument(r *mux.Router) {
+	r.Use(m.Middleware)
+	r.NotFoundHandler = m.Middleware(http.NotFoundHandler())
+	r.MethodNotAllowedHandler = m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
+
+// bookingCreated counts the booking of the studio
+func (m *Metrics) bookingCreated(studioID int, booking structs.Booking) {
+	if m != nil {
+		m.bookingsCreated.Inc(strconv.Itoa(studioID), booking.Status)
+	}
+}
+
+// bookingRejected counts the booking request of the studio which failed with the error
+func (m *Metrics) bookingRejected(studioID int, err error) {
+	if m != nil {
+		m.bookingsRejected.Inc(strconv.Itoa(studioID), errorCode(err))
+	}
+}
+
+// classCreated counts the class created in the studio
+func (m *Metrics) classCreated(studioID int) {
+	if m != nil {
+		m.classesCreated.Inc(strconv.Itoa(studioID))
+	}
+}
+
+// occupancySamples returns the booked count, or the capacity, of the sessions which start today
+// or later in the studio's timezone. Only studios whose store is open are read, a scrape never
+// opens one.
+func occupancySamples(studios *processors.StudioProcessor, capacity bool) ([]metrics.Sample, error) {
+	var samples []metrics.Sample
+	for _, scoped := range studios.Opened() {
+		sessions, err := scoped.Bookings.GetBookedSessions(today(scoped.Location))
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			value := session.Booked
+			if capacity {
+				value = session.Capacity
+			}
+			samples = append(samples, metrics.Sample{
+				Labels: []string{strconv.Itoa(scoped.Studio.ID), session.ClassName, formatSession(session.ClassDate.In(scoped.Location))},
+				Value:  float64(value),
+			})
+		}
+	}
+	return samples, nil
+}
+
+// formatSession formats the start of a session like requests name it, a date alone for all day sessions
+func formatSession(session time.Time) string {
+	if session.Hour() == 0 && session.Minute() == 0 {
+		return session.Format(DATEFORMAT)
+	}
+	return session.Format(SESSIONFORMAT)
+}
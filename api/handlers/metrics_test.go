@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestMetricsRouter creates a router with counted class, booking and metrics routes on studios
+// kept in memory
+func newTestMetricsRouter(t *testing.T) *mux.Router {
+	t.Helper()
+
+	studioProcessor := processors.NewStudioProcessor(storage.NewMemoryStore(), func(structs.Studio, *time.Location) (storage.Store, error) {
+		return storage.NewMemoryStore(), nil
+	})
+	if _, err := studioProcessor.SetupDefaultStudio("default", "UTC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	metrics := NewMetrics(studioProcessor)
+	s := NewStudiosHandler(studioProcessor, metrics)
+
+	r := mux.NewRouter()
+	metrics.Instrument(r)
+	r.HandleFunc("/metrics", metrics.MetricsHandler).Methods(http.MethodGet)
+	r.HandleFunc("/classes", s.Scoped((*Handler).CreateClassHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/bookings", s.Scoped((*Handler).BookClassHandler)).Methods(http.MethodPost)
+	return r
+}
+
+func TestMetricsHandler(t *testing.T) {
+	r := newTestMetricsRouter(t)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	classBody := fmt.Sprintf(`{"class_name": "Pilates", "start_date": "%s", "end_date": "%s", "capacity": 4}`, futureDate(1), futureDate(3))
+	checkResponseCode(t, http.StatusCreated, serve("POST", "/classes", classBody).Code)
+
+	booking := fmt.Sprintf(`{"member_name": "Sai Kumar", "class_name": "Pilates", "class_date": "%s"}`, futureDate(2))
+	checkResponseCode(t, http.StatusOK, serve("POST", "/bookings", booking).Code)
+	checkResponseCode(t, http.StatusConflict, serve("POST", "/bookings", booking).Code)
+	checkResponseCode(t, http.StatusBadRequest, serve("POST", "/bookings", `{"class_name": "Pilates"}`).Code)
+	checkResponseCode(t, http.StatusNotFound, serve("GET", "/nowhere", "").Code)
+	checkResponseCode(t, http.StatusMethodNotAllowed, serve("DELETE", "/classes", "").Code)
+
+	response := serve("GET", "/metrics", "")
+	checkResponseCode(t, http.StatusOK, response.Code)
+	for _, line := range []string{
+		`glofox_http_requests_total{route="/bookings",method="POST",status="409"} 1`,
+		`glofox_http_request_duration_seconds_count{route="/classes",method="POST"} 1`,
+		`glofox_http_requests_total{route="unmatched",method="GET",status="404"} 1`,
+		`glofox_http_requests_total{route="unmatched",method="DELETE",status="405"} 1`,
+		`glofox_bookings_created_total{studio="1",status="booked"} 1`,
+		`glofox_bookings_rejected_total{studio="1",reason="already_booked"} 1`,
+		`glofox_bookings_rejected_total{studio="1",reason="invalid_request"} 1`,
+		`glofox_classes_created_total{studio="1"} 1`,
+		fmt.Sprintf(`glofox_class_session_bookings{studio="1",class_name="pilates",date="%s"} 1`, futureDate(2)),
+		fmt.Sprintf(`glofox_class_session_capacity{studio="1",class_name="pilates",date="%s"} 4`, futureDate(2)),
+	} {
+		if !strings.Contains(response.Body.String(), line+"\n") {
+			t.Errorf("expected the metrics to contain %s, got\n%s", line, response.Body.String())
+		}
+	}
+}
+
+func TestMetricsHandler_OpenStudiosOnly(t *testing.T) {
+	var opened []int
+	studioProcessor := processors.NewStudioProcessor(storage.NewMemoryStore(), func(studio structs.Studio, _ *time.Location) (storage.Store, error) {
+		opened = append(opened, studio.ID)
+		return storage.NewMemoryStore(), nil
+	})
+	if _, err := studioProcessor.SetupDefaultStudio("default", "UTC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := studioProcessor.CreateStudio("London", "Europe/London"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := studioProcessor.Processors(processors.DefaultStudioID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The scrape reads the default studio and leaves the store of London closed
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	NewMetrics(studioProcessor).MetricsHandler(rr, req)
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if len(opened) != 1 || opened[0] != processors.DefaultStudioID {
+		t.Errorf("expected only the default studio to be opened, got %v", opened)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+)
+
+// RequestIDHeader carries the id which correlates the log lines of a request
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches the ids of callers which are kept, other ids are replaced so
+// they cannot forge log lines
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestLogger gives every request an id, echoed in the X-Request-ID response header, and a
+// logger which adds it to each log line. The id of the caller is kept when it sends one.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			requestLogger := logger.With("request_id", id)
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(recorder, r.WithContext(utils.NewContext(r.Context(), requestLogger)))
+
+			requestLogger.Info("request served",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", recorder.status,
+				"duration", time.Since(start),
+			)
+		})
+	}
+}
+
+// newRequestID returns a random 128 bit id in hex
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+)
+
+// executeLoggedRequest serves the request with a handler which logs a line, the log lines are
+// written to the returned buffer as JSON
+func executeLoggedRequest(req *http.Request) (*httptest.ResponseRecorder, *bytes.Buffer) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	handler := RequestLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.FromContext(r.Context()).Info("handled")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr, &logs
+}
+
+// logRecords decodes the JSON log lines
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("expected a JSON log line, got %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestLogger_GeneratesID(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/classes", nil)
+	response, logs := executeLoggedRequest(req)
+
+	id := response.Header().Get(RequestIDHeader)
+	if len(id) != 32 {
+		t.Fatalf("expected a generated request id, got %q", id)
+	}
+
+	// Every line of the request carries its id, the last one records the response
+	records := logRecords(t, logs)
+	if len(records) != 2 {
+		t.Fatalf("expected two log lines, got %v", records)
+	}
+	for _, record := range records {
+		if record["request_id"] != id {
+			t.Errorf("expected request id %s, got %v", id, record)
+		}
+	}
+	if served := records[1]; served["status"] != float64(http.StatusTeapot) || served["path"] != "/classes" {
+		t.Errorf("expected the served request logged, got %v", served)
+	}
+}
+
+func TestRequestLogger_KeepsCallerID(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/classes", nil)
+	req.Header.Set(RequestIDHeader, "trace-42")
+	if response, _ := executeLoggedRequest(req); response.Header().Get(RequestIDHeader) != "trace-42" {
+		t.Fatalf("expected the caller's request id, got %q", response.Header().Get(RequestIDHeader))
+	}
+
+	// Ids which could forge log lines are replaced
+	req.Header.Set(RequestIDHeader, "bad id\nlevel=ERROR")
+	if response, _ := executeLoggedRequest(req); len(response.Header().Get(RequestIDHeader)) != 32 {
+		t.Fatalf("expected a generated request id, got %q", response.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateInstructorHandler handles adding an instructor who can be given classes
+func (h *Handler) CreateInstructorHandler(w http.ResponseWriter, r *http.Request) {
+	var request structs.InstructorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	instructor, err := h.classes.CreateInstructor(request.Name, request.Email)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("created instructor", "instructor_id", instructor.ID, "instructor_name", instructor.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(instructor)
+}
+
+// GetInstructorsHandler handles listing every instructor
+func (h *Handler) GetInstructorsHandler(w http.ResponseWriter, r *http.Request) {
+	instructors, err := h.classes.ListInstructors()
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(instructors)
+}
+
+// GetInstructorHandler handles fetching an instructor by its id
+func (h *Handler) GetInstructorHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	instructor, err := h.classes.GetInstructor(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(instructor)
+}
+
+// CreateRoomHandler handles adding a room which can host classes
+func (h *Handler) CreateRoomHandler(w http.ResponseWriter, r *http.Request) {
+	var request structs.RoomRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	room, err := h.classes.CreateRoom(request.Name)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("created room", "room_id", room.ID, "room_name", room.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(room)
+}
+
+// GetRoomsHandler handles listing every room
+func (h *Handler) GetRoomsHandler(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.classes.ListRooms()
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rooms)
+}
+
+// GetRoomHandler handles fetching a room by its id
+func (h *Handler) GetRoomHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	room, err := h.classes.GetRoom(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(room)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestResourceHandlers(t *testing.T) {
+	handler := newTestHandler(storage.NewMemoryStore(), time.Local)
+	response := executeJSON(handler, "POST", "/instructors", `{"email": "not-an-email"}`)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+	checkFieldError(t, response, "name", "required")
+	checkFieldError(t, response, "email", "email")
+
+	response = executeJSON(handler, "POST", "/instructors", `{"name": "Priya", "email": "priya@example.com"}`)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var instructor structs.Instructor
+	json.Unmarshal(response.Body.Bytes(), &instructor)
+
+	response = executeJSON(handler, "POST", "/rooms", `{"name": "Studio A"}`)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var room structs.Room
+	json.Unmarshal(response.Body.Bytes(), &room)
+
+	response = executeJSON(handler, "GET", "/rooms", "")
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var rooms []structs.Room
+	json.Unmarshal(response.Body.Bytes(), &rooms)
+	if len(rooms) != 1 || rooms[0] != room {
+		t.Errorf("Expected room Studio A, got %v", response.Body.String())
+	}
+	checkResponseCode(t, http.StatusOK, executeJSON(handler, "GET", fmt.Sprintf("/instructors/%d", instructor.ID), "").Code)
+	checkResponseCode(t, http.StatusNotFound, executeJSON(handler, "GET", "/instructors/99999", "").Code)
+
+	classBody := func(name string, instructorID, roomID int) string {
+		return fmt.Sprintf(`{"class_name": "%s", "start_date": "%s", "end_date": "%s", "capacity": 10,
+			"schedule": {"start_times": ["07:00"], "duration": 60}, "instructor_id": %d, "room_id": %d}`,
+			name, futureDate(5), futureDate(6), instructorID, roomID)
+	}
+	checkResponseCode(t, http.StatusNotFound, executeJSON(handler, "POST", "/classes", classBody("Yoga", instructor.ID, 99999)).Code)
+
+	response = executeJSON(handler, "POST", "/classes", classBody("Yoga", instructor.ID, room.ID))
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var class structs.Class
+	json.Unmarshal(response.Body.Bytes(), &class)
+	if class.InstructorID != instructor.ID || class.RoomID != room.ID {
+		t.Errorf("Expected class with instructor %d in room %d, got %v", instructor.ID, room.ID, response.Body.String())
+	}
+
+	// An other class in the same room at the same time is rejected with the clashing class
+	response = executeJSON(handler, "POST", "/classes", classBody("Spin", 0, room.ID))
+	checkResponseCode(t, http.StatusConflict, response.Code)
+	var body structs.ErrorResponse
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if body.Code != "class_conflict" || !strings.Contains(body.Details, fmt.Sprintf(`class "yoga" (id %d) has the same room %d`, class.ID, room.ID)) {
+		t.Errorf("Expected a conflict naming class yoga, got %v", response.Body.String())
+	}
+
+	checkResponseCode(t, http.StatusCreated, executeJSON(handler, "POST", "/classes", classBody("Spin", 0, 0)).Code)
+}
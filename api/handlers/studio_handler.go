@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// StudiosHandler serves the studio registry and passes studio scoped requests to a Handler
+// on top of the processors of the studio
+type StudiosHandler struct {
+	studios *processors.StudioProcessor
+	metrics *Metrics
+}
+
+// NewStudiosHandler creates a handler which delegates to the studio processor and counts
+// bookings and classes in the metrics, metrics may be nil
+func NewStudiosHandler(studios *processors.StudioProcessor, metrics *Metrics) *StudiosHandler {
+	return &StudiosHandler{studios: studios, metrics: metrics}
+}
+
+// Scoped serves the request with handle on the Handler of the studio in the studioID path
+// variable, routes without the variable are served by the default studio
+func (s *StudiosHandler) Scoped(handle func(*Handler, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := processors.DefaultStudioID
+		if value, ok := mux.Vars(r)["studioID"]; ok {
+			var err error
+			if id, err = strconv.Atoi(value); err != nil {
+				SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+				return
+			}
+		}
+
+		studio, err := s.studios.Processors(id)
+		if err != nil {
+			SendErrorResponse(w, r, err)
+			return
+		}
+		h := NewHandler(studio.Classes, studio.Bookings, studio.Members, studio.Location)
+		h.studioID, h.metrics = id, s.metrics
+		handle(h, w, r)
+	}
+}
+
+// CreateStudioHandler handles registering a new studio
+func (s *StudiosHandler) CreateStudioHandler(w http.ResponseWriter, r *http.Request) {
+	var request structs.StudioRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidBody, err))
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+
+	// Validate the request fields, every invalid field is reported at once
+	fields, err := validateRequest(request)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+	if len(fields) > 0 {
+		sendFieldErrors(w, r, fields)
+		return
+	}
+
+	studio, err := s.studios.CreateStudio(request.Name, request.Timezone)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	utils.FromContext(r.Context()).Info("created studio", "studio_id", studio.ID, "studio_name", studio.Name, "timezone", studio.Timezone)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(studio)
+}
+
+// GetStudiosHandler handles listing the studios
+func (s *StudiosHandler) GetStudiosHandler(w http.ResponseWriter, r *http.Request) {
+	studios, err := s.studios.ListStudios()
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(studios)
+}
+
+// GetStudioHandler handles fetching a studio by its id
+func (s *StudiosHandler) GetStudioHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["studioID"])
+	if err != nil {
+		SendErrorResponse(w, r, fmt.Errorf("%w: %v", errInvalidID, err))
+		return
+	}
+
+	studio, err := s.studios.GetStudio(id)
+	if err != nil {
+		SendErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(studio)
+}
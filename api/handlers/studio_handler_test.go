@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"github.com/gorilla/mux"
+)
+
+// newTestStudiosHandler creates a studios handler which keeps every studio in memory
+func newTestStudiosHandler(t *testing.T) *StudiosHandler {
+	t.Helper()
+
+	studioProcessor := processors.NewStudioProcessor(storage.NewMemoryStore(), func(structs.Studio, *time.Location) (storage.Store, error) {
+		return storage.NewMemoryStore(), nil
+	})
+	if _, err := studioProcessor.SetupDefaultStudio("default", "UTC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return NewStudiosHandler(studioProcessor, nil)
+}
+
+// executeStudioRequest performs the request against the studio routes
+func executeStudioRequest(s *StudiosHandler, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	r := mux.NewRouter()
+
+	r.HandleFunc("/studios", s.CreateStudioHandler).Methods(http.MethodPost)
+	r.HandleFunc("/studios", s.GetStudiosHandler).Methods(http.MethodGet)
+	r.HandleFunc("/studios/{studioID:[0-9]+}", s.GetStudioHandler).Methods(http.MethodGet)
+	for _, prefix := range []string{"", "/studios/{studioID:[0-9]+}"} {
+		r.HandleFunc(prefix+"/classes", s.Scoped((*Handler).CreateClassHandler)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/classes", s.Scoped((*Handler).GetClassesHandler)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/bookings", s.Scoped((*Handler).BookClassHandler)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/bookings/{classDate:[0-9]{4}-[0-9]{2}-[0-9]{2}}", s.Scoped((*Handler).GetBookingsByDateHandler)).Methods("GET")
+	}
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+// createTestStudio registers a studio through the API and returns it
+func createTestStudio(t *testing.T, s *StudiosHandler, name, timezone string) structs.Studio {
+	t.Helper()
+
+	payload := fmt.Sprintf(`{"name": "%s", "timezone": "%s"}`, name, timezone)
+	req, _ := http.NewRequest("POST", "/studios", bytes.NewBuffer([]byte(payload)))
+	response := executeStudioRequest(s, req)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var studio structs.Studio
+	json.Unmarshal(response.Body.Bytes(), &studio)
+	return studio
+}
+
+func TestCreateStudioHandler(t *testing.T) {
+	studios := newTestStudiosHandler(t)
+	studio := createTestStudio(t, studios, "Dublin", "Europe/Dublin")
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/studios/%d", studio.ID), nil)
+	response := executeStudioRequest(studios, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var found structs.Studio
+	json.Unmarshal(response.Body.Bytes(), &found)
+	if found.Name != "Dublin" || found.Timezone != "Europe/Dublin" {
+		t.Errorf("Expected the Dublin studio, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/studios", nil)
+	response = executeStudioRequest(studios, req)
+	var all []structs.Studio
+	json.Unmarshal(response.Body.Bytes(), &all)
+	if len(all) != 2 {
+		t.Errorf("Expected the default and the Dublin studio, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("POST", "/studios", bytes.NewBuffer([]byte(`{"name": "Nowhere", "timezone": "Mars/Olympus"}`)))
+	checkResponseCode(t, http.StatusBadRequest, executeStudioRequest(studios, req).Code)
+
+	req, _ = http.NewRequest("GET", "/studios/99/classes", nil)
+	checkResponseCode(t, http.StatusNotFound, executeStudioRequest(studios, req).Code)
+}
+
+func TestStudios_Isolated(t *testing.T) {
+	studios := newTestStudiosHandler(t)
+	dublin := createTestStudio(t, studios, "Dublin", "Europe/Dublin")
+	london := createTestStudio(t, studios, "London", "Europe/London")
+
+	// Yoga on the same dates in both studios and the default studio does not clash
+	classBody := fmt.Sprintf(`{"class_name": "Yoga", "start_date": "%s", "end_date": "%s", "capacity": 1}`, futureDate(5), futureDate(6))
+	for _, prefix := range []string{fmt.Sprintf("/studios/%d", dublin.ID), fmt.Sprintf("/studios/%d", london.ID), ""} {
+		req, _ := http.NewRequest("POST", prefix+"/classes", bytes.NewBuffer([]byte(classBody)))
+		checkResponseCode(t, http.StatusCreated, executeStudioRequest(studios, req).Code)
+	}
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/studios/%d/classes", dublin.ID), bytes.NewBuffer([]byte(classBody)))
+	checkResponseCode(t, http.StatusConflict, executeStudioRequest(studios, req).Code)
+
+	// The only spot of the Dublin class does not use up the London one
+	payload := fmt.Sprintf(`{"member_name": "Sai Kumar", "class_date": "%s", "class_name": "Yoga"}`, futureDate(5))
+	for _, studio := range []structs.Studio{dublin, london} {
+		req, _ = http.NewRequest("POST", fmt.Sprintf("/studios/%d/bookings", studio.ID), bytes.NewBuffer([]byte(payload)))
+		checkResponseCode(t, http.StatusOK, executeStudioRequest(studios, req).Code)
+	}
+
+	// Bookings are only listed in their own studio
+	req, _ = http.NewRequest("GET", "/bookings/"+futureDate(5), nil)
+	response := executeStudioRequest(studios, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var page structs.BookingPage
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if len(page.Bookings) != 0 {
+		t.Errorf("Expected no bookings in the default studio, got %v", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/studios/%d/bookings/%s", london.ID, futureDate(5)), nil)
+	response = executeStudioRequest(studios, req)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	page = structs.BookingPage{}
+	json.Unmarshal(response.Body.Bytes(), &page)
+	if len(page.Bookings) != 1 || page.Bookings[0].ClassName != "yoga" {
+		t.Errorf("Expected one yoga booking in London, got %v", response.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"github.com/go-playground/validator"
+)
+
+// fieldErrors collects every invalid field of a request so they are reported at once
+type fieldErrors []structs.FieldError
+
+// add records that the field failed the rule
+func (f *fieldErrors) add(field, rule, message string) {
+	*f = append(*f, structs.FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// has reports whether the field already failed a rule
+func (f fieldErrors) has(field string) bool {
+	for _, e := range f {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldName names struct fields in validation errors by their JSON name
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// validateRequest validates the request and returns an error for every invalid field,
+// the error is only set when the request could not be validated at all
+func validateRequest(request interface{}) (fieldErrors, error) {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, err
+	}
+
+	var fields fieldErrors
+	for _, e := range validationErrors {
+		// The namespace starts with the name of the request struct, e.g. ClassRequest.schedule.duration
+		field := e.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		fields.add(field, e.Tag(), fieldMessage(field, e))
+	}
+	return fields, nil
+}
+
+// fieldMessage describes the rule the field failed
+func fieldMessage(field string, e validator.FieldError) string {
+	switch e.Tag() {
+	case "required", "required_without":
+		return fmt.Sprintf("%s is required", field)
+	case "dateformat":
+		return fmt.Sprintf("%s must be a date in YYYY-MM-DD format", field)
+	case "timeformat":
+		return fmt.Sprintf("%s must be a time in HH:MM format", field)
+	case "sessionformat":
+		return fmt.Sprintf("%s must be a date in YYYY-MM-DD or YYYY-MM-DDTHH:MM format", field)
+	case "timezone":
+		return fmt.Sprintf("%s must be an IANA timezone, e.g. Europe/Dublin", field)
+	case "email":
+		return fmt.Sprintf("%s must be an email address", field)
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in E.164 format, e.g. +14155552671", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, e.Param())
+	case "min":
+		if e.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must have at least %s entries", field, e.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, e.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", field, e.Param())
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// sendFieldErrors responds with a bad request listing every invalid field
+func sendFieldErrors(w http.ResponseWriter, r *http.Request, fields fieldErrors) {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	sendError(w, r, fmt.Errorf("%w: %s", errInvalidRequest, strings.Join(messages, "; ")), fields)
+}
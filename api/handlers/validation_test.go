@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// checkFieldError checks that the response reports the field as failing the rule
+func checkFieldError(t *testing.T, response *httptest.ResponseRecorder, field, rule string) {
+	t.Helper()
+
+	var body structs.ErrorResponse
+	json.Unmarshal(response.Body.Bytes(), &body)
+	for _, e := range body.Fields {
+		if e.Field == field && e.Rule == rule {
+			return
+		}
+	}
+	t.Errorf("Expected %s to fail %s, got %v", field, rule, response.Body.String())
+}
+
+func TestCreateClassHandler_AllFieldErrors(t *testing.T) {
+	payload := `{"class_name": "", "start_date": "2020-01-10", "end_date": "2020-01-01", "schedule": {"weekdays": ["someday"], "start_times": ["7pm"], "duration": 0}}`
+	req, _ := http.NewRequest("POST", "/classes", bytes.NewBuffer([]byte(payload)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	var body structs.ErrorResponse
+	json.Unmarshal(response.Body.Bytes(), &body)
+	if len(body.Fields) != 8 {
+		t.Errorf("Expected 8 invalid fields, got %v", response.Body.String())
+	}
+
+	// Struct rules, nested fields and the cross field date checks are reported together
+	checkFieldError(t, response, "class_name", "required")
+	checkFieldError(t, response, "capacity", "required")
+	checkFieldError(t, response, "schedule.weekdays[0]", "oneof")
+	checkFieldError(t, response, "schedule.start_times[0]", "timeformat")
+	checkFieldError(t, response, "schedule.duration", "required")
+	checkFieldError(t, response, "start_date", "class_in_past")
+	checkFieldError(t, response, "end_date", "class_in_past")
+	checkFieldError(t, response, "start_date", "invalid_class_dates")
+}
+
+func TestBookClass_AllFieldErrors(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/bookings", bytes.NewBuffer([]byte(`{"class_date": "tomorrow"}`)))
+	response := executeRequest(req)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	checkFieldError(t, response, "class_name", "required")
+	checkFieldError(t, response, "member_name", "required_without")
+	checkFieldError(t, response, "class_date", "sessionformat")
+}
+
+func TestValidateRequest_NotAStruct(t *testing.T) {
+	// A value the validator cannot handle is an error instead of a panic
+	fields, err := validateRequest(nil)
+	if err == nil || fields != nil {
+		t.Errorf("Expected an error without fields, got %v, %v", fields, err)
+	}
+
+	fields, err = validateRequest(structs.MemberRequest{Name: "Ravi", Email: "ravi@example.com"})
+	if err != nil || len(fields) != 0 {
+		t.Errorf("Expected a valid request, got %v, %v", fields, err)
+	}
+}
@@ -1,24 +1,95 @@
 package routers
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/saikumar-neelam/glofox_studio/api/handlers"
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
 
 	"github.com/gorilla/mux"
 )
 
-// SetupRouter sets up the API routes using gorilla/mux
-func SetupRouter() *mux.Router {
-	r := mux.NewRouter()
+// SetupRouter sets up the API routes using gorilla/mux. Studio data is served under
+// /studios/{studioID}, the same routes without the prefix serve the default studio.
+// Owners manage classes and members, members can only book, cancel and view their own bookings.
+// Every request is counted in the metrics, which are served at /metrics, and logged with the
+// logger and its request id. The health and version probes skip authentication and logging.
+func SetupRouter(s *handlers.StudiosHandler, a *handlers.Authorizer, m *handlers.Metrics, h *handlers.HealthHandler, logger *slog.Logger) *mux.Router {
+	root := mux.NewRouter()
+	m.Instrument(root)
 
-	// Route to create a new class
-	r.HandleFunc("/classes", handlers.CreateClassHandler).Methods(http.MethodPost)
+	//Routes to probe the liveness and readiness of the service and its build
+	root.HandleFunc("/healthz", h.LivenessHandler).Methods(http.MethodGet)
+	root.HandleFunc("/readyz", h.ReadinessHandler).Methods(http.MethodGet)
+	root.HandleFunc("/version", h.VersionHandler).Methods(http.MethodGet)
 
-	//Route to book a class
-	r.HandleFunc("/bookings", handlers.BookClassHandler).Methods(http.MethodPost)
+	r := root.NewRoute().Subrouter()
+	r.Use(handlers.RequestLogger(logger))
 
-	//Route to get the number of bookings of different classes on specific date
-	r.HandleFunc("/bookings/{classDate}", handlers.GetBookingsByDateHandler).Methods("GET")
-	return r
+	//Route to scrape the metrics of every studio
+	r.HandleFunc("/metrics", a.RequireAllStudios(m.MetricsHandler)).Methods(http.MethodGet)
+	owner, anyone := []auth.Role{auth.RoleOwner}, []auth.Role{auth.RoleOwner, auth.RoleMember}
+
+	//Routes to register, list and fetch studios
+	r.HandleFunc("/studios", a.RequireAllStudios(s.CreateStudioHandler)).Methods(http.MethodPost)
+	r.HandleFunc("/studios", a.RequireAllStudios(s.GetStudiosHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/studios/{studioID:[0-9]+}", a.Require(s.GetStudioHandler, anyone...)).Methods(http.MethodGet)
+
+	for _, prefix := range []string{"", "/studios/{studioID:[0-9]+}"} {
+		// Route to create a new class
+		r.HandleFunc(prefix+"/classes", a.Require(s.Scoped((*handlers.Handler).CreateClassHandler), owner...)).Methods(http.MethodPost)
+
+		// Routes to list, fetch, change and delete classes
+		r.HandleFunc(prefix+"/classes", a.Require(s.Scoped((*handlers.Handler).GetClassesHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/classes/{id}", a.Require(s.Scoped((*handlers.Handler).GetClassHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/classes/{id}", a.Require(s.Scoped((*handlers.Handler).UpdateClassHandler), owner...)).Methods(http.MethodPatch)
+		r.HandleFunc(prefix+"/classes/{id}", a.Require(s.Scoped((*handlers.Handler).DeleteClassHandler), owner...)).Methods(http.MethodDelete)
+
+		//Routes to add, list and fetch the instructors and rooms classes are scheduled with
+		r.HandleFunc(prefix+"/instructors", a.Require(s.Scoped((*handlers.Handler).CreateInstructorHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/instructors", a.Require(s.Scoped((*handlers.Handler).GetInstructorsHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/instructors/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).GetInstructorHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/rooms", a.Require(s.Scoped((*handlers.Handler).CreateRoomHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/rooms", a.Require(s.Scoped((*handlers.Handler).GetRoomsHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/rooms/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).GetRoomHandler), anyone...)).Methods(http.MethodGet)
+
+		//Routes to get the capacity, bookings, waitlist and remaining spots of class sessions
+		r.HandleFunc(prefix+"/classes/{id}/occupancy", a.Require(s.Scoped((*handlers.Handler).GetClassOccupancyHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/occupancy/{classDate:[0-9]{4}-[0-9]{2}-[0-9]{2}}", a.Require(s.Scoped((*handlers.Handler).GetOccupancyByDateHandler), anyone...)).Methods(http.MethodGet)
+
+		//Route to list the booked members of a class session and whether they checked in
+		r.HandleFunc(prefix+"/classes/{id}/sessions/{session}/roster", a.Require(s.Scoped((*handlers.Handler).GetRosterHandler), owner...)).Methods(http.MethodGet)
+
+		//Route to book a class
+		r.HandleFunc(prefix+"/bookings", a.Require(s.Scoped((*handlers.Handler).BookClassHandler), anyone...)).Methods(http.MethodPost)
+
+		//Route to list the bookings page by page
+		r.HandleFunc(prefix+"/bookings", a.Require(s.Scoped((*handlers.Handler).GetBookingsHandler), anyone...)).Methods(http.MethodGet)
+
+		//Route to get the number of bookings of different classes on specific date
+		r.HandleFunc(prefix+"/bookings/{classDate:[0-9]{4}-[0-9]{2}-[0-9]{2}}", a.Require(s.Scoped((*handlers.Handler).GetBookingsByDateHandler), owner...)).Methods("GET")
+
+		//Routes to fetch and cancel a booking by its id
+		r.HandleFunc(prefix+"/bookings/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).GetBookingHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/bookings/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).CancelBookingHandler), anyone...)).Methods(http.MethodDelete)
+
+		//Routes to record whether the member of a booking showed up
+		r.HandleFunc(prefix+"/bookings/{id:[0-9]+}/check-in", a.Require(s.Scoped((*handlers.Handler).CheckInHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/bookings/{id:[0-9]+}/no-show", a.Require(s.Scoped((*handlers.Handler).MarkNoShowHandler), owner...)).Methods(http.MethodPost)
+
+		//Routes to register, fetch and change members
+		r.HandleFunc(prefix+"/members", a.Require(s.Scoped((*handlers.Handler).CreateMemberHandler), owner...)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).GetMemberHandler), anyone...)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}", a.Require(s.Scoped((*handlers.Handler).UpdateMemberHandler), anyone...)).Methods(http.MethodPatch)
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}/strikes", a.Require(s.Scoped((*handlers.Handler).ResetStrikesHandler), owner...)).Methods(http.MethodDelete)
+
+		//Routes to assign the membership of a member and to look up what is left of it
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}/membership", a.Require(s.Scoped((*handlers.Handler).AssignMembershipHandler), owner...)).Methods(http.MethodPut)
+		r.HandleFunc(prefix+"/members/{id:[0-9]+}/balance", a.Require(s.Scoped((*handlers.Handler).GetBalanceHandler), anyone...)).Methods(http.MethodGet)
+
+		//Route to get the schedule of a member
+		r.HandleFunc(prefix+"/members/{name}/bookings", a.Require(s.Scoped((*handlers.Handler).GetMemberBookingsHandler), anyone...)).Methods(http.MethodGet)
+	}
+	return root
 }
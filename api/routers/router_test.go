@@ -0,0 +1,127 @@
+package routers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/api/handlers"
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// testOwnerKey is the api key of an owner of every studio accepted by the test router
+const testOwnerKey = "test-owner-key"
+
+// newTestRouter creates the router of studios kept in memory which requires JWTs or the owner api key, ready reports
+// the readiness of the service and the request logs are written to the returned buffer
+func newTestRouter(t *testing.T, ready func(context.Context) error) (http.Handler, *bytes.Buffer) {
+	t.Helper()
+
+	studioProcessor := processors.NewStudioProcessor(storage.NewMemoryStore(), func(structs.Studio, *time.Location) (storage.Store, error) {
+		return storage.NewMemoryStore(), nil
+	})
+	if _, err := studioProcessor.SetupDefaultStudio("default", "UTC"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	authenticator, err := auth.NewAuthenticator([][]byte{[]byte("test-key")}, []auth.APIKey{{Key: testOwnerKey, Principal: auth.Principal{Role: auth.RoleOwner}}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var logs bytes.Buffer
+	metrics := handlers.NewMetrics(studioProcessor)
+	router := SetupRouter(handlers.NewStudiosHandler(studioProcessor, metrics), handlers.NewAuthorizer(authenticator),
+		metrics, handlers.NewHealthHandler(ready), slog.New(slog.NewJSONHandler(&logs, nil)))
+	return router, &logs
+}
+
+// serve performs a GET request of the path without credentials
+func serve(router http.Handler, path string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSetupRouter_Probes(t *testing.T) {
+	router, logs := newTestRouter(t, func(context.Context) error { return nil })
+
+	// The probes are served without credentials and are not logged
+	for path, status := range map[string]string{"/healthz": "ok", "/readyz": "ready"} {
+		response := serve(router, path)
+		var body map[string]string
+		json.Unmarshal(response.Body.Bytes(), &body)
+		if response.Code != http.StatusOK || body["status"] != status {
+			t.Errorf("expected %s to be %s, got %d %s", path, status, response.Code, response.Body)
+		}
+		if response.Header().Get(handlers.RequestIDHeader) != "" {
+			t.Errorf("expected %s to skip request logging", path)
+		}
+	}
+
+	response := serve(router, "/version")
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"commit"`) {
+		t.Errorf("expected the build info, got %d %s", response.Code, response.Body)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no request logs, got %s", logs)
+	}
+
+	// Every other route still requires credentials and is logged
+	if response := serve(router, "/classes"); response.Code != http.StatusUnauthorized || response.Header().Get(handlers.RequestIDHeader) == "" {
+		t.Errorf("expected an unauthorized and logged request, got %d", response.Code)
+	}
+	if logs.Len() == 0 {
+		t.Errorf("expected the request to be logged")
+	}
+}
+
+func TestSetupRouter_NotReady(t *testing.T) {
+	router, _ := newTestRouter(t, func(context.Context) error { return errors.New("database is locked") })
+
+	response := serve(router, "/readyz")
+	if response.Code != http.StatusServiceUnavailable || !strings.Contains(response.Body.String(), `"code":"not_ready"`) {
+		t.Fatalf("expected the service not to be ready, got %d %s", response.Code, response.Body)
+	}
+	if response := serve(router, "/healthz"); response.Code != http.StatusOK {
+		t.Fatalf("expected the service to be alive, got %d", response.Code)
+	}
+}
+
+func TestSetupRouter_Metrics(t *testing.T) {
+	router, _ := newTestRouter(t, func(context.Context) error { return nil })
+
+	serve(router, "/studios/1/classes")
+	serve(router, "/nowhere")
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPut, "/classes", nil)
+	router.ServeHTTP(rr, req)
+
+	// Requests are counted by the template of their route, unmatched ones under a fixed route
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("X-API-Key", testOwnerKey)
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected the metrics, got %d %s", rr.Code, rr.Body)
+	}
+	for _, line := range []string{
+		`glofox_http_requests_total{route="/studios/{studioID:[0-9]+}/classes",method="GET",status="401"} 1`,
+		`glofox_http_requests_total{route="unmatched",method="GET",status="404"} 1`,
+		`glofox_http_requests_total{route="unmatched",method="PUT",status="405"} 1`,
+	} {
+		if !strings.Contains(rr.Body.String(), line+"\n") {
+			t.Errorf("expected the metrics to contain %s, got\n%s", line, rr.Body)
+		}
+	}
+}
@@ -1,17 +1,207 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" //the studio timezone must load on hosts without a zoneinfo database
 
+	"github.com/saikumar-neelam/glofox_studio/api/handlers"
 	"github.com/saikumar-neelam/glofox_studio/api/routers"
+	"github.com/saikumar-neelam/glofox_studio/internal/auth"
+	"github.com/saikumar-neelam/glofox_studio/internal/config"
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/saikumar-neelam/glofox_studio/internal/utils"
 )
 
 func main() {
-	// Setup the router
-	router := routers.SetupRouter()
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	logger, closeLog, err := utils.NewLogger(utils.LogOptions{Level: cfg.LogLevel, Format: cfg.LogFormat, File: cfg.LogFile})
+	if err != nil {
+		log.Fatalf("Failed to setup logging: %v", err)
+	}
+
+	err = run(cfg, logger)
+	if err != nil {
+		logger.Error("server failed", "error", err)
+	} else {
+		logger.Info("server stopped")
+	}
+	closeLog()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// run serves the API until SIGINT or SIGTERM, then drains in-flight requests and flushes the stores
+func run(cfg config.Config, logger *slog.Logger) (err error) {
+	// Setup authentication, without keys every request is served as it is once insecure is set
+	authenticator, err := newAuthenticator(cfg.JWTKeys, cfg.APIKeysFile)
+	if err != nil {
+		return fmt.Errorf("failed to setup authentication: %w", err)
+	}
+	if !authenticator.Enabled() {
+		if !cfg.Insecure {
+			return errors.New("no JWT keys or api keys configured, set -jwt-keys or -api-keys-file, or -insecure to serve requests without authentication")
+		}
+		logger.Warn("no JWT keys or api keys configured, requests are not authenticated")
+	}
+
+	// Dates and session times are wall clock times of the studio
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q: %w", cfg.Timezone, err)
+	}
+
+	// Setup the storage backend, it holds the studio registry and the data of the default studio
+	var store storage.Store
+	var studios storage.StudioRepository
+	switch cfg.Storage {
+	case "memory":
+		memoryStore := storage.NewMemoryStore()
+		store, studios = memoryStore, memoryStore
+	case "file":
+		fileStore, err := storage.NewFileStore(cfg.DataFile, location)
+		if err != nil {
+			return fmt.Errorf("failed to open data file %s: %w", cfg.DataFile, err)
+		}
+		store, studios = fileStore, fileStore
+	case "sqlite":
+		sqliteStore, err := openSQLiteStore(cfg.SQLiteFile, location)
+		if err != nil {
+			return fmt.Errorf("failed to open database %s: %w", cfg.SQLiteFile, err)
+		}
+		store, studios = sqliteStore, sqliteStore
+	}
+	//the default store is only closed here, the studio processor leaves it open
+	defer func() { err = errors.Join(err, store.Close()) }()
+
+	// Every other studio keeps its data in a store of its own next to the default one
+	open := func(studio structs.Studio, location *time.Location) (storage.Store, error) {
+		if studio.ID == processors.DefaultStudioID {
+			return store, nil
+		}
+		switch cfg.Storage {
+		case "file":
+			return storage.NewFileStore(studioPath(cfg.DataFile, studio.ID), location)
+		case "sqlite":
+			return openSQLiteStore(studioPath(cfg.SQLiteFile, studio.ID), location)
+		default:
+			return storage.NewMemoryStore(), nil
+		}
+	}
+
+	// Setup the processors and the router
+	studioProcessor := processors.NewStudioProcessor(studios, open)
+	studioProcessor.SetBookingRules(processors.BookingRules{
+		MaxStrikes:    cfg.MaxStrikes,
+		CheckInBefore: cfg.CheckInBefore,
+		CheckInAfter:  cfg.CheckInAfter,
+
+		RequireMembership: cfg.RequireMembership,
+	})
+	if _, err := studioProcessor.SetupDefaultStudio("default", cfg.Timezone); err != nil {
+		return fmt.Errorf("failed to setup the default studio: %w", err)
+	}
+	metrics := handlers.NewMetrics(studioProcessor)
+	router := routers.SetupRouter(handlers.NewStudiosHandler(studioProcessor, metrics), handlers.NewAuthorizer(authenticator), metrics, handlers.NewHealthHandler(store.Check), logger)
+
+	server := &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
 
 	// Start the server
-	log.Println("Starting server on :8080")
-	log.Fatal(http.ListenAndServe(":8080", router))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if cfg.TLS() {
+			serveErr <- server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+			return
+		}
+		serveErr <- server.ListenAndServe()
+	}()
+	logger.Info("starting server", "addr", cfg.Addr, "tls", cfg.TLS(), "storage", cfg.Storage, "timezone", location.String())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal stops the process right away
+	stop()
+	logger.Info("shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	shutdownErr := server.Shutdown(shutdownCtx)
+
+	// Flush the stores once no request uses them anymore, the default store last when run returns
+	return errors.Join(shutdownErr, studioProcessor.Close())
+}
+
+// newAuthenticator creates the authenticator of the comma separated JWT keys and the api keys file
+func newAuthenticator(jwtKeys, apiKeysFile string) (*auth.Authenticator, error) {
+	var keys [][]byte
+	for _, key := range strings.Split(jwtKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, []byte(key))
+		}
+	}
+
+	var apiKeys []auth.APIKey
+	if apiKeysFile != "" {
+		var err error
+		if apiKeys, err = auth.LoadAPIKeys(apiKeysFile); err != nil {
+			return nil, err
+		}
+	}
+	return auth.NewAuthenticator(keys, apiKeys)
+}
+
+// openSQLiteStore opens the database at path and brings its schema up to date
+func openSQLiteStore(path string, location *time.Location) (*storage.SQLiteStore, error) {
+	store, err := storage.NewSQLiteStore(path, location)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bring the schema up to date before serving requests
+	if err := store.Migrate(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// studioPath returns the file holding the data of the studio, e.g. glofox.studio-2.db for glofox.db
+func studioPath(path string, studioID int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.studio-%d%s", strings.TrimSuffix(path, ext), studioID, ext)
 }
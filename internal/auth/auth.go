@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/processors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Role is what the caller of the API is allowed to do
+type Role string
+
+const (
+	// RoleOwner manages the classes, members and bookings of a studio
+	RoleOwner Role = "owner"
+	// RoleMember books and cancels classes for themselves
+	RoleMember Role = "member"
+)
+
+var (
+	ErrMissingCredentials = &processors.Error{Code: "missing_credentials", Kind: processors.KindUnauthorized, Message: "missing bearer token or api key"}
+	ErrInvalidCredentials = &processors.Error{Code: "invalid_credentials", Kind: processors.KindUnauthorized, Message: "invalid bearer token or api key"}
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Role     Role `json:"role"`
+	MemberID int  `json:"member_id,omitempty"` //the member acting, required for the member role
+	StudioID int  `json:"studio_id,omitempty"` //the studio the credentials are for, 0 is every studio for owners
+}
+
+// Claims are the claims of the signed JWTs accepted by the API
+type Claims struct {
+	Principal
+	jwt.RegisteredClaims
+}
+
+// APIKey is an entry of the api keys file
+type APIKey struct {
+	Key string `json:"key"`
+	Principal
+}
+
+// Authenticator verifies the bearer JWTs and api keys of requests
+type Authenticator struct {
+	jwtKeys [][]byte
+	apiKeys map[[sha256.Size]byte]Principal
+}
+
+// NewAuthenticator creates an authenticator which accepts JWTs signed with any of the HMAC
+// keys and the api keys, several JWT keys allow rotating them
+func NewAuthenticator(jwtKeys [][]byte, apiKeys []APIKey) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: make(map[[sha256.Size]byte]Principal)}
+	for _, key := range jwtKeys {
+		if len(key) > 0 {
+			a.jwtKeys = append(a.jwtKeys, key)
+		}
+	}
+
+	//only hashes of the api keys are kept, the lookup does not compare the keys byte by byte
+	for _, apiKey := range apiKeys {
+		if apiKey.Key == "" {
+			return nil, errors.New("api key cannot be empty")
+		}
+		principal, err := apiKey.Principal.normalize()
+		if err != nil {
+			return nil, err
+		}
+		a.apiKeys[sha256.Sum256([]byte(apiKey.Key))] = principal
+	}
+	return a, nil
+}
+
+// LoadAPIKeys reads the JSON array of api keys in the file
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiKeys []APIKey
+	if err := json.Unmarshal(data, &apiKeys); err != nil {
+		return nil, fmt.Errorf("api keys file %s: %w", path, err)
+	}
+	return apiKeys, nil
+}
+
+// Enabled reports whether any JWT key or api key is configured, requests are not
+// authenticated otherwise
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.jwtKeys) > 0 || len(a.apiKeys) > 0)
+}
+
+// Authenticate returns the caller of the request from its X-API-Key header or its
+// Authorization bearer token
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		principal, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Principal{}, ErrInvalidCredentials
+		}
+		return principal, nil
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || len(a.jwtKeys) == 0 {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return a.parseToken(strings.TrimSpace(token))
+}
+
+// parseToken verifies the signature and expiry of the JWT and returns its principal
+func (a *Authenticator) parseToken(token string) (Principal, error) {
+	for _, key := range a.jwtKeys {
+		var claims Claims
+		_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) { return key, nil },
+			jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}), jwt.WithExpirationRequired())
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			//signed with another of the keys
+			continue
+		}
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		principal, err := claims.Principal.normalize()
+		if err != nil {
+			return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		return principal, nil
+	}
+	return Principal{}, ErrInvalidCredentials
+}
+
+// normalize checks the role of the principal, members act as a member of a single studio
+// which is the default studio when the credentials do not name one
+func (p Principal) normalize() (Principal, error) {
+	switch p.Role {
+	case RoleOwner:
+	case RoleMember:
+		if p.MemberID <= 0 {
+			return Principal{}, errors.New("member credentials need a member_id")
+		}
+		if p.StudioID == 0 {
+			p.StudioID = processors.DefaultStudioID
+		}
+	default:
+		return Principal{}, fmt.Errorf("unknown role %q", p.Role)
+	}
+	return p, nil
+}
+
+// CanAccessStudio reports whether the principal may act in the studio
+func (p Principal) CanAccessStudio(studioID int) bool {
+	return p.StudioID == 0 || p.StudioID == studioID
+}
+
+// HasRole reports whether the principal has one of the roles
+func (p Principal) HasRole(roles ...Role) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal of an authenticated request
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signToken signs a JWT for the principal with the key, expiring after ttl
+func signToken(t *testing.T, key string, principal Principal, ttl time.Duration) string {
+	t.Helper()
+
+	claims := Claims{Principal: principal, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl))}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return token
+}
+
+// authenticate authenticates a request with the header
+func authenticate(a *Authenticator, header, value string) (Principal, error) {
+	r, _ := http.NewRequest("GET", "/classes", nil)
+	if value != "" {
+		r.Header.Set(header, value)
+	}
+	return a.Authenticate(r)
+}
+
+func TestAuthenticate_JWT(t *testing.T) {
+	a, err := NewAuthenticator([][]byte{[]byte("new-key"), []byte("old-key")}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Tokens signed with any of the keys are accepted and members default to the default studio
+	for _, key := range []string{"new-key", "old-key"} {
+		principal, err := authenticate(a, "Authorization", "Bearer "+signToken(t, key, Principal{Role: RoleMember, MemberID: 7}, time.Hour))
+		if err != nil || principal != (Principal{Role: RoleMember, MemberID: 7, StudioID: 1}) {
+			t.Errorf("expected member 7 of studio 1, got %v, %v", principal, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{"missing", "", ErrMissingCredentials},
+		{"unknown key", "Bearer " + signToken(t, "other-key", Principal{Role: RoleOwner}, time.Hour), ErrInvalidCredentials},
+		{"expired", "Bearer " + signToken(t, "new-key", Principal{Role: RoleOwner}, -time.Minute), ErrInvalidCredentials},
+		{"member without id", "Bearer " + signToken(t, "new-key", Principal{Role: RoleMember}, time.Hour), ErrInvalidCredentials},
+		{"unknown role", "Bearer " + signToken(t, "new-key", Principal{Role: "admin"}, time.Hour), ErrInvalidCredentials},
+		{"unsigned", "Bearer eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJyb2xlIjoib3duZXIifQ.", ErrInvalidCredentials},
+		{"basic scheme", "Basic b3duZXI6c2VjcmV0", ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticate(a, "Authorization", tt.value); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	os.WriteFile(path, []byte(`[{"key": "owner-key", "role": "owner", "studio_id": 2}, {"key": "member-key", "role": "member", "member_id": 3}]`), 0600)
+
+	apiKeys, err := LoadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	a, err := NewAuthenticator(nil, apiKeys)
+	if err != nil || !a.Enabled() {
+		t.Fatalf("expected an enabled authenticator, got %v", err)
+	}
+
+	if principal, err := authenticate(a, "X-API-Key", "owner-key"); err != nil || principal != (Principal{Role: RoleOwner, StudioID: 2}) {
+		t.Errorf("expected the owner of studio 2, got %v, %v", principal, err)
+	}
+	if principal, err := authenticate(a, "X-API-Key", "member-key"); err != nil || !principal.CanAccessStudio(1) || principal.CanAccessStudio(2) {
+		t.Errorf("expected member 3 of studio 1, got %v, %v", principal, err)
+	}
+	if _, err := authenticate(a, "X-API-Key", "guessed-key"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected %v, got %v", ErrInvalidCredentials, err)
+	}
+
+	// Bearer tokens are rejected when no JWT key is configured
+	if _, err := authenticate(a, "Authorization", "Bearer "+signToken(t, "", Principal{Role: RoleOwner}, time.Hour)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected %v, got %v", ErrInvalidCredentials, err)
+	}
+
+	if _, err := NewAuthenticator(nil, []APIKey{{Key: "member-key", Principal: Principal{Role: RoleMember}}}); err == nil {
+		t.Errorf("expected an error for a member key without member_id")
+	}
+	if a, _ := NewAuthenticator(nil, nil); a.Enabled() {
+		t.Errorf("expected authentication to be disabled without keys")
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables of the settings, e.g. GLOFOX_ADDR for -addr
+const EnvPrefix = "GLOFOX_"
+
+// Config holds the settings of the server
+type Config struct {
+	Addr            string        //address the server listens on
+	ReadTimeout     time.Duration //maximum duration for reading a request including its body
+	WriteTimeout    time.Duration //maximum duration before timing out writes of a response
+	IdleTimeout     time.Duration //maximum time to wait for the next request on keep-alive connections
+	ShutdownTimeout time.Duration //maximum time to drain in-flight requests on shutdown
+	LogLevel        string        //debug, info, warn or error
+	LogFormat       string        //text or json
+	LogFile         string        //logs go to stdout when empty
+	TLSCertFile     string        //the server uses TLS when both the certificate and the key are set
+	TLSKeyFile      string
+
+	Storage    string //memory, file or sqlite
+	DataFile   string
+	SQLiteFile string
+	Timezone   string //IANA timezone of the default studio
+
+	MaxStrikes    int           //late cancellations and no-shows which block a member from booking, 0 never blocks
+	CheckInBefore time.Duration //how long before the start of a session members can check in
+	CheckInAfter  time.Duration //how long after the start of a session members can check in
+
+	RequireMembership bool //members without a membership cannot book
+
+	JWTKeys     string //comma separated HMAC keys
+	APIKeysFile string
+	Insecure    bool //serve requests without authentication when no keys are configured
+}
+
+// Load reads the settings from the command line args, the environment and the config file. Flags
+// given in args win over environment variables, which win over the file, which wins over the defaults.
+// The file is named by -config or GLOFOX_CONFIG, its keys are the flag names.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	cfg := Config{
+		Addr:            ":8080",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 30 * time.Second,
+		LogLevel:        "info",
+		LogFormat:       "text",
+		Storage:         "memory",
+		DataFile:        "./glofox_data.json",
+		SQLiteFile:      "./glofox.db",
+		Timezone:        "UTC",
+		MaxStrikes:      3,
+		CheckInBefore:   30 * time.Minute,
+		CheckInAfter:    15 * time.Minute,
+	}
+
+	fs := flag.NewFlagSet("glofox", flag.ContinueOnError)
+	configFile := fs.String("config", "", "YAML or JSON file with settings keyed by flag name")
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "address the server listens on")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "maximum duration for reading a request")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "maximum duration for writing a response")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "maximum time to keep an idle connection open")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "maximum time to drain in-flight requests on shutdown")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "minimum level of the logs: debug, info, warn or error")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "format of the logs: text or json")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "file the logs are appended to, stdout when empty")
+	fs.StringVar(&cfg.TLSCertFile, "tls-cert", cfg.TLSCertFile, "TLS certificate file, serves HTTPS together with -tls-key")
+	fs.StringVar(&cfg.TLSKeyFile, "tls-key", cfg.TLSKeyFile, "TLS private key file")
+	fs.StringVar(&cfg.Storage, "storage", cfg.Storage, "storage backend to use: memory, file or sqlite")
+	fs.StringVar(&cfg.DataFile, "data-file", cfg.DataFile, "data file used by the file storage backend")
+	fs.StringVar(&cfg.SQLiteFile, "sqlite-file", cfg.SQLiteFile, "database file used by the sqlite storage backend")
+	fs.StringVar(&cfg.Timezone, "timezone", cfg.Timezone, "IANA timezone of the default studio, e.g. Europe/Dublin")
+	fs.IntVar(&cfg.MaxStrikes, "max-strikes", cfg.MaxStrikes, "late cancellations and no-shows which block a member from booking, 0 never blocks")
+	fs.DurationVar(&cfg.CheckInBefore, "check-in-before", cfg.CheckInBefore, "how long before the start of a session members can check in")
+	fs.DurationVar(&cfg.CheckInAfter, "check-in-after", cfg.CheckInAfter, "how long after the start of a session members can check in")
+	fs.BoolVar(&cfg.RequireMembership, "require-membership", cfg.RequireMembership, "members without a membership cannot book, otherwise they book without using credits")
+	fs.StringVar(&cfg.JWTKeys, "jwt-keys", cfg.JWTKeys, "comma separated HMAC keys which sign the accepted JWTs")
+	fs.StringVar(&cfg.APIKeysFile, "api-keys-file", cfg.APIKeysFile, "JSON file with the accepted api keys and their role")
+	fs.BoolVar(&cfg.Insecure, "insecure", cfg.Insecure, "serve every request without authentication when no JWT keys or api keys are configured")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	//settings given on the command line are not overridden
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	path := *configFile
+	if path == "" {
+		path = getenv(EnvPrefix + "CONFIG")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		for name, value := range values {
+			if err := set(fs, given, name, value); err != nil {
+				return Config{}, fmt.Errorf("config file %s: %w", path, err)
+			}
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, name := getenv(envName(f.Name)), f.Name
+		if value != "" && err == nil && name != "config" {
+			if setErr := set(fs, given, name, value); setErr != nil {
+				err = fmt.Errorf("environment variable %s: %w", envName(name), setErr)
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.validate()
+}
+
+// TLS reports whether the server is served over HTTPS
+func (c Config) TLS() bool {
+	return c.TLSCertFile != ""
+}
+
+// validate checks the settings which cannot be checked by their type
+func (c Config) validate() error {
+	switch c.Storage {
+	case "memory", "file", "sqlite":
+	default:
+		return fmt.Errorf("unknown storage backend %q, use memory, file or sqlite", c.Storage)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return fmt.Errorf("unknown log level %q, use debug, info, warn or error", c.LogLevel)
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("unknown log format %q, use text or json", c.LogFormat)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("tls-cert and tls-key must be set together")
+	}
+	if c.MaxStrikes < 0 {
+		return fmt.Errorf("max-strikes cannot be negative, got %d", c.MaxStrikes)
+	}
+	if c.CheckInBefore < 0 || c.CheckInAfter < 0 {
+		return fmt.Errorf("check-in-before and check-in-after cannot be negative, got %s and %s", c.CheckInBefore, c.CheckInAfter)
+	}
+	for name, timeout := range map[string]time.Duration{
+		"read-timeout": c.ReadTimeout, "write-timeout": c.WriteTimeout, "idle-timeout": c.IdleTimeout, "shutdown-timeout": c.ShutdownTimeout,
+	} {
+		if timeout <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", name, timeout)
+		}
+	}
+	return nil
+}
+
+// set sets the flag unless it was given on the command line
+func set(fs *flag.FlagSet, given map[string]bool, name, value string) error {
+	if fs.Lookup(name) == nil || name == "config" {
+		return fmt.Errorf("unknown setting %q", name)
+	}
+	if given[name] {
+		return nil
+	}
+	return fs.Set(name, value)
+}
+
+// envName returns the environment variable of the flag, e.g. GLOFOX_READ_TIMEOUT for read-timeout
+func envName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// readFile reads the settings of a YAML or JSON file, lists are joined with commas
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		//keys may use underscores like the environment variables
+		name = strings.ReplaceAll(name, "_", "-")
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+			continue
+		}
+		values[name] = fmt.Sprint(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv func of the variables
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+// writeFile writes the content to a file of the name in a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Addr != ":8080" || cfg.Storage != "memory" || cfg.LogFile != "" || cfg.LogLevel != "info" || cfg.LogFormat != "text" || cfg.Timezone != "UTC" || cfg.MaxStrikes != 3 {
+		t.Fatalf("expected the default settings, got %+v", cfg)
+	}
+	if cfg.ReadTimeout != 15*time.Second || cfg.ShutdownTimeout != 30*time.Second || cfg.TLS() || cfg.CheckInBefore != 30*time.Minute || cfg.CheckInAfter != 15*time.Minute {
+		t.Fatalf("expected the default timeouts without TLS, got %+v", cfg)
+	}
+	if cfg.Insecure {
+		t.Fatalf("expected authentication to be required by default")
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "glofox.yaml", `
+addr: ":9000"
+read_timeout: 5s
+write-timeout: 7s
+storage: file
+log-file: /var/log/glofox.log
+jwt-keys: [first, second]
+require-membership: true
+`)
+
+	// The file is overridden by the environment, which is overridden by the flags
+	cfg, err := Load([]string{"-config", path, "-addr", ":9100"}, env(map[string]string{
+		"GLOFOX_ADDR":         ":9200",
+		"GLOFOX_READ_TIMEOUT": "6s",
+		"GLOFOX_INSECURE":     "true",
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Addr != ":9100" {
+		t.Fatalf("expected the flag address, got %s", cfg.Addr)
+	}
+	if cfg.ReadTimeout != 6*time.Second {
+		t.Fatalf("expected the environment read timeout, got %s", cfg.ReadTimeout)
+	}
+	if cfg.WriteTimeout != 7*time.Second || cfg.Storage != "file" || cfg.LogFile != "/var/log/glofox.log" {
+		t.Fatalf("expected the file settings, got %+v", cfg)
+	}
+	if cfg.JWTKeys != "first,second" {
+		t.Fatalf("expected the listed keys joined, got %s", cfg.JWTKeys)
+	}
+	if !cfg.RequireMembership {
+		t.Fatalf("expected memberships to be required")
+	}
+	if !cfg.Insecure {
+		t.Fatalf("expected the environment to allow requests without authentication")
+	}
+}
+
+func TestLoad_JSONFileFromEnvironment(t *testing.T) {
+	path := writeFile(t, "glofox.json", `{"storage": "sqlite", "sqlite-file": "/data/glofox.db", "idle-timeout": "2m"}`)
+
+	cfg, err := Load(nil, env(map[string]string{"GLOFOX_CONFIG": path}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Storage != "sqlite" || cfg.SQLiteFile != "/data/glofox.db" || cfg.IdleTimeout != 2*time.Minute {
+		t.Fatalf("expected the JSON file settings, got %+v", cfg)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"unknown log level", []string{"-log-level", "verbose"}, nil, "unknown log level"},
+		{"unknown log format", nil, map[string]string{"GLOFOX_LOG_FORMAT": "xml"}, "unknown log format"},
+		{"unknown storage", []string{"-storage", "redis"}, nil, "unknown storage backend"},
+		{"certificate without key", []string{"-tls-cert", "cert.pem"}, nil, "must be set together"},
+		{"zero timeout", []string{"-write-timeout", "0s"}, nil, "write-timeout must be positive"},
+		{"negative strikes", nil, map[string]string{"GLOFOX_MAX_STRIKES": "-1"}, "max-strikes cannot be negative"},
+		{"negative check-in window", []string{"-check-in-after", "-5m"}, nil, "check-in-after cannot be negative"},
+		{"invalid environment value", nil, map[string]string{"GLOFOX_READ_TIMEOUT": "soon"}, "GLOFOX_READ_TIMEOUT"},
+		{"unknown file setting", []string{"-config", writeFile(t, "glofox.yml", "port: 8080")}, nil, `unknown setting "port"`},
+		{"unsupported file", []string{"-config", writeFile(t, "glofox.toml", "")}, nil, "must be .yaml, .yml or .json"},
+		{"missing file", []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, nil, "no such file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Load(test.args, env(test.env)); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("expected an error containing %q, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	cfg, err := Load([]string{"-tls-cert", "cert.pem", "-tls-key", "key.pem"}, env(nil))
+	if err != nil || !cfg.TLS() {
+		t.Fatalf("expected TLS settings, got %+v, %v", cfg, err)
+	}
+}
+
+func TestLoad_Help(t *testing.T) {
+	// The usage goes to stderr, -h is not an error of the settings
+	if _, err := Load([]string{"-h"}, env(nil)); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected %v, got %v", flag.ErrHelp, err)
+	}
+}
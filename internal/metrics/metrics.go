@@ -0,0 +1,294 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds in seconds of the latency histogram buckets
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Sample is a value of a metric with one value per label of the metric
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// collector writes the samples of a metric in the text exposition format
+type collector interface {
+	write(w io.Writer) error
+}
+
+// Registry holds the metrics which are exposed together
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Write writes every metric of the registry in the text exposition format, in the order
+// the metrics were registered
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, c := range collectors {
+		if err := c.write(buffered); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// Handler serves the metrics of the registry, nothing but the error is served when a metric
+// cannot be collected
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var body bytes.Buffer
+		if err := r.Write(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		body.WriteTo(w)
+	}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// desc is the name, help and label names of a metric
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+// writeHeader writes the HELP and TYPE lines of the metric
+func (d desc) writeHeader(w io.Writer) {
+	help := strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(d.help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, help, d.name, d.kind)
+}
+
+// writeSample writes a sample line of the metric, extra is appended to the labels
+func (d desc) writeSample(w io.Writer, suffix string, values []string, extra string, value float64) {
+	pairs := make([]string, 0, len(values)+1)
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labels[i], escapeLabel(value)))
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+
+	labels := ""
+	if len(pairs) > 0 {
+		labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(w, "%s%s%s %s\n", d.name, suffix, labels, formatValue(value))
+}
+
+// key joins the label values of a sample into a map key
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// CounterVec is a counter with a value per combination of label values
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*Sample
+}
+
+// NewCounterVec creates a counter with the labels and registers it
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, kind: "counter", labels: labels}, values: make(map[string]*Sample)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the counter of the label values
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the counter of the label values, counters never decrease
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.name))
+	}
+	key := c.key(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sample, ok := c.values[key]
+	if !ok {
+		sample = &Sample{Labels: append([]string(nil), values...)}
+		c.values[key] = sample
+	}
+	sample.Value += delta
+}
+
+// Value returns the counter of the label values
+func (c *CounterVec) Value(values ...string) float64 {
+	key := c.key(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sample, ok := c.values[key]; ok {
+		return sample.Value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeHeader(w)
+	for _, key := range sortedKeys(c.values) {
+		sample := c.values[key]
+		c.writeSample(w, "", sample.Labels, "", sample.Value)
+	}
+	return nil
+}
+
+// HistogramVec is a histogram with a distribution per combination of label values
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+// histogram is the distribution of the observations of one combination of label values
+type histogram struct {
+	labels []string
+	counts []uint64 //observations per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec creates a histogram with the bucket upper bounds and labels and registers it
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &HistogramVec{desc: desc{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, values: make(map[string]*histogram)}
+	r.register(h)
+	return h
+}
+
+// Observe adds the value to the distribution of the label values
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	dist, ok := h.values[key]
+	if !ok {
+		dist = &histogram{labels: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = dist
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		dist.counts[i]++
+	}
+	dist.count++
+	dist.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.writeHeader(w)
+	for _, key := range sortedKeys(h.values) {
+		dist := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += dist.counts[i]
+			h.writeSample(w, "_bucket", dist.labels, fmt.Sprintf(`le="%s"`, formatValue(bound)), float64(cumulative))
+		}
+		h.writeSample(w, "_bucket", dist.labels, `le="+Inf"`, float64(dist.count))
+		h.writeSample(w, "_sum", dist.labels, "", dist.sum)
+		h.writeSample(w, "_count", dist.labels, "", float64(dist.count))
+	}
+	return nil
+}
+
+// GaugeFunc is a gauge whose samples are collected each time the metrics are written
+type GaugeFunc struct {
+	desc
+	collect func() ([]Sample, error)
+}
+
+// NewGaugeFunc creates a gauge with the labels whose samples are returned by collect and registers it
+func (r *Registry) NewGaugeFunc(name, help string, collect func() ([]Sample, error), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) error {
+	samples, err := g.collect()
+	if err != nil {
+		return fmt.Errorf("collecting %s: %w", g.name, err)
+	}
+
+	g.writeHeader(w)
+	for _, sample := range samples {
+		g.key(sample.Labels)
+		g.writeSample(w, "", sample.Labels, "", sample.Value)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the samples in order, so the output is stable
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeLabel escapes a label value as the text format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatValue formats a sample value as the text format requires
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	latency := registry.NewHistogramVec("latency_seconds", "Time taken.", []float64{1, 0.1}, "route")
+	registry.NewGaugeFunc("sessions", "Booked sessions.", func() ([]Sample, error) {
+		return []Sample{{Labels: []string{`yoga "hot"`}, Value: 3}}, nil
+	}, "class_name")
+
+	requests.Inc("/classes", "200")
+	requests.Add(2, "/bookings", "409")
+	latency.Observe(0.05, "/classes")
+	latency.Observe(0.5, "/classes")
+	latency.Observe(2, "/classes")
+
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/bookings",status="409"} 2
+requests_total{route="/classes",status="200"} 1
+# HELP latency_seconds Time taken.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/classes",le="0.1"} 1
+latency_seconds_bucket{route="/classes",le="1"} 2
+latency_seconds_bucket{route="/classes",le="+Inf"} 3
+latency_seconds_sum{route="/classes"} 2.55
+latency_seconds_count{route="/classes"} 3
+# HELP sessions Booked sessions.
+# TYPE sessions gauge
+sessions{class_name="yoga \"hot\""} 3
+`
+	if out.String() != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+	if value := requests.Value("/bookings", "409"); value != 2 {
+		t.Fatalf("expected 2, got %v", value)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests served.").Inc()
+
+	rr := httptest.NewRecorder()
+	registry.Handler()(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("expected the metrics, got %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if rr.Body.String() != "# HELP requests_total Requests served.\n# TYPE requests_total counter\nrequests_total 1\n" {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+
+	// A metric which cannot be collected fails the whole scrape
+	registry.NewGaugeFunc("sessions", "Booked sessions.", func() ([]Sample, error) {
+		return nil, errors.New("store unavailable")
+	})
+	rr = httptest.NewRecorder()
+	registry.Handler()(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+}
@@ -2,62 +2,751 @@ package processors
 
 import (
 	"errors"
-	"sync"
+	"sort"
 	"time"
 
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
 	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 )
 
 const DATEFORMAT = "2006-01-02"
 
-var DateWiseoverallBookings = make(map[string]map[string][]structs.Booking)
+var (
+	ErrClassFull         = &Error{Code: "class_full", Kind: KindConflict, Message: "class is fully booked for the selected date"}
+	ErrBookingNotFound   = &Error{Code: "booking_not_found", Kind: KindNotFound, Message: "booking not found"}
+	ErrClassNotScheduled = &Error{Code: "class_not_scheduled", Kind: KindNotFound, Message: "class is not scheduled on the selected date"}
+	ErrAlreadyBooked     = &Error{Code: "already_booked", Kind: KindConflict, Message: "member has already booked the class on the selected date"}
+	// ErrBookingInPast is returned for a past date or a session which already started
+	ErrBookingInPast = &Error{Code: "booking_in_past", Kind: KindInvalid, Message: "booking cannot be for a past date or a session which already started"}
+	// ErrTooManyStrikes is returned when a member reached the strikes of BookingRules.MaxStrikes
+	ErrTooManyStrikes     = &Error{Code: "too_many_strikes", Kind: KindForbidden, Message: "member has too many late cancellations and no-shows to book"}
+	ErrNotBooked          = &Error{Code: "booking_not_confirmed", Kind: KindConflict, Message: "booking is on the waitlist and not confirmed"}
+	ErrAttendanceRecorded = &Error{Code: "attendance_recorded", Kind: KindConflict, Message: "attendance of the booking is already recorded"}
+	ErrSessionNotStarted  = &Error{Code: "session_not_started", Kind: KindConflict, Message: "session of the booking has not started yet"}
+	ErrBookingCancelled   = &Error{Code: "booking_cancelled", Kind: KindConflict, Message: "booking is already cancelled"}
+	// ErrCheckInClosed is returned for a check-in outside the window of BookingRules around the session start
+	ErrCheckInClosed = &Error{Code: "check_in_closed", Kind: KindConflict, Message: "check-in is only open around the start of the session"}
+)
+
+// BookingRules are the booking rules shared by every class of a studio
+type BookingRules struct {
+	MaxStrikes int //strikes which block a registered member from booking, 0 never blocks
+
+	//members check in from CheckInBefore before the start of a session until CheckInAfter
+	//after it, all day sessions accept check-ins until the end of the day
+	CheckInBefore time.Duration
+	CheckInAfter  time.Duration
+
+	//members without a membership cannot book, otherwise they book without using credits
+	RequireMembership bool
+}
+
+// BookingProcessor implements booking the classes of a class processor on top of the booking repository.
+// Bookings and waitlists are grouped by date and class name, waitlisted members are
+// kept in FIFO order and promoted when a booking is cancelled.
+type BookingProcessor struct {
+	classes  *ClassProcessor
+	members  *MemberProcessor
+	bookings storage.BookingRepository
+
+	rules BookingRules
+	now   func() time.Time //current time, cancellations and attendance are checked against it
+}
+
+// NewBookingProcessor creates a booking processor which books the classes of the class processor
+// and stores bookings in the repository, bookings of registered members are linked to the member.
+// Both class and booking processors share the lock of the class processor, so a class cannot
+// change while it is being booked, and the class processor promotes waitlists through it.
+func NewBookingProcessor(classes *ClassProcessor, members *MemberProcessor, bookings storage.BookingRepository) *BookingProcessor {
+	p := &BookingProcessor{classes: classes, members: members, bookings: bookings, now: time.Now}
+	classes.promote = p.promoteNext
+	return p
+}
+
+// SetRules changes the booking rules, later bookings are checked against them
+func (p *BookingProcessor) SetRules(rules BookingRules) {
 
-var bookingsMutex sync.Mutex
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	p.rules = rules
+}
 
 // bookclass is a function which implements booking a class for a member
 // input name and class date
 // output booking struct, error
-func BookClass(class_name, member_name string, classDate time.Time) (structs.Booking, error) {
+func (p *BookingProcessor) BookClass(class_name, member_name string, classDate time.Time) (structs.Booking, error) {
+
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	class, err := p.classes.findScheduled(class_name, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+
+	if err := p.checkDuplicate(class_name, member_name, classDate); err != nil {
+		return structs.Booking{}, err
+	}
+
+	if err := p.checkStrikes(member_name); err != nil {
+		return structs.Booking{}, err
+	}
+
+	full, err := p.isClassFull(class, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	if full {
+		return structs.Booking{}, ErrClassFull
+	}
+	return p.addEntry(class_name, member_name, classDate, structs.BookingStatusBooked)
+}
+
+// JoinWaitlist books the class when a spot is still available, otherwise the member
+// is added to the end of the waitlist for the class on that date
+// input name and class date
+// output booking struct with status and waitlist position, error
+func (p *BookingProcessor) JoinWaitlist(class_name, member_name string, classDate time.Time) (structs.Booking, error) {
+
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	class, err := p.classes.findScheduled(class_name, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+
+	if err := p.checkDuplicate(class_name, member_name, classDate); err != nil {
+		return structs.Booking{}, err
+	}
+
+	if err := p.checkStrikes(member_name); err != nil {
+		return structs.Booking{}, err
+	}
+
+	full, err := p.isClassFull(class, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	if !full {
+		return p.addEntry(class_name, member_name, classDate, structs.BookingStatusBooked)
+	}
+
+	waiting, err := p.addEntry(class_name, member_name, classDate, structs.BookingStatusWaitlisted)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	return p.withWaitlistPosition(waiting)
+}
+
+// GetBooking returns the booking with the id, waitlisted bookings include their position
+// input booking id
+// output booking struct, error
+func (p *BookingProcessor) GetBooking(id int) (structs.Booking, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	booking, err := p.bookings.GetBooking(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Booking{}, ErrBookingNotFound
+	}
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	return p.withWaitlistPosition(booking)
+}
+
+// GetMemberBookings returns the bookings, waitlist entries and cancelled bookings of the member ordered by class date
+// input member name
+// output list of bookings, error
+func (p *BookingProcessor) GetMemberBookings(member_name string) ([]structs.Booking, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	bookings, err := p.bookings.ListBookingsByMember(member_name)
+	if err != nil {
+		return nil, err
+	}
+	if bookings == nil {
+		bookings = []structs.Booking{}
+	}
+
+	for i := range bookings {
+		if bookings[i], err = p.withWaitlistPosition(bookings[i]); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].ClassDate.Before(bookings[j].ClassDate)
+	})
+	return bookings, nil
+}
 
-	defer bookingsMutex.Unlock()
-	bookingsMutex.Lock()
-	newBooking := structs.Booking{MemberName: member_name, ClassDate: classDate, ClassName: class_name}
+// CancelBookingByID cancels the booking with the id, releasing its spot like CancelBooking
+// input booking id
+// output cancelled booking, error
+func (p *BookingProcessor) CancelBookingByID(id int) (structs.Booking, error) {
 
-	date := classDate.Format(DATEFORMAT)
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
 
-	//check already date wise any bookings are there
-	//if no bookings found for the date then initialize it
-	if _, ok := DateWiseoverallBookings[date]; !ok {
-		DateWiseoverallBookings[date] = make(map[string][]structs.Booking)
+	booking, err := p.bookings.GetBooking(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Booking{}, ErrBookingNotFound
+	}
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	return p.cancel(booking)
+}
+
+// CancelBooking cancels the member's booking for the class on the date and
+// promotes the first member on the waitlist into the released spot.
+// A member who is only waitlisted leaves the waitlist instead.
+// input name and class date
+// output cancelled booking, error
+func (p *BookingProcessor) CancelBooking(class_name, member_name string, classDate time.Time) (structs.Booking, error) {
+
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	bookings, err := p.bookings.ListBookings(class_name, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	waiting, err := p.bookings.ListWaitlist(class_name, classDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+
+	for _, booking := range append(bookings, waiting...) {
+		if structs.NormalizeMemberName(booking.MemberName) == structs.NormalizeMemberName(member_name) {
+			return p.cancel(booking)
+		}
+	}
+	return structs.Booking{}, ErrBookingNotFound
+}
+
+// cancel stores the cancelled status of the booking or waitlist entry, a released spot is
+// given to the first member on the waitlist. A booking cancelled inside the cancellation
+// window of its class is late cancelled and gives the member a strike, otherwise the credit
+// it used is refunded. The cancellation, promotion and member changes are stored in one step.
+// Caller must hold p.classes.mu.
+func (p *BookingProcessor) cancel(booking structs.Booking) (structs.Booking, error) {
+	switch booking.Status {
+	case structs.BookingStatusAttended, structs.BookingStatusNoShow:
+		return structs.Booking{}, ErrAttendanceRecorded
+	case structs.BookingStatusCancelled, structs.BookingStatusLateCancelled:
+		return structs.Booking{}, ErrBookingCancelled
+	}
+
+	defer p.members.mu.Unlock()
+	p.members.mu.Lock()
+
+	var change storage.BookingChange
+	var late bool
+	var err error
+	if booking.Status != structs.BookingStatusWaitlisted {
+		if late, err = p.isLateCancel(booking); err != nil {
+			return structs.Booking{}, err
+		}
+
+		//the longest waiting member who can pay is promoted into the released spot
+		if change, err = p.promotion(booking.ClassName, booking.ClassDate); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+
+	var members []structs.Member
+	if late {
+		booking.Status = structs.BookingStatusLateCancelled
+		members, err = p.strike(booking)
+	} else {
+		booking.Status = structs.BookingStatusCancelled
+		members, err = p.refund(booking)
+	}
+	if err != nil {
+		return structs.Booking{}, err
+	}
+
+	change.Update = append([]structs.Booking{booking}, change.Update...)
+	change.Members = append(change.Members, members...)
+	if _, err := p.bookings.ApplyChange(change); err != nil {
+		return structs.Booking{}, err
+	}
+	return booking, nil
+}
+
+// promotion returns the change which promotes the first member on the waitlist of the class
+// session who can pay for it with a credit, members who cannot keep waiting. Entries which
+// already paid when joining the waitlist are not charged again. The change is empty when
+// nobody can be promoted. Caller must hold p.classes.mu and p.members.mu.
+func (p *BookingProcessor) promotion(className string, session time.Time) (storage.BookingChange, error) {
+	waiting, err := p.bookings.ListWaitlist(className, session)
+	if err != nil {
+		return storage.BookingChange{}, err
 	}
 
-	//check if class not found or not on the date.
-	if _, ok := DateWiseoverallBookings[date][class_name]; !ok {
-		//looping through classes to find any classname matches with the start and end date range
-		for _, existingClass := range classes {
-			if existingClass.ClassName == class_name && ((classDate.Equal(existingClass.StartDate) || classDate.After(existingClass.StartDate)) && (classDate.Equal(existingClass.EndDate) || classDate.Before(existingClass.EndDate))) {
-				// Initialize the class bookings for the date if it does not exist
-				DateWiseoverallBookings[date][class_name] = []structs.Booking{}
-				DateWiseoverallBookings[date][class_name] = append(DateWiseoverallBookings[date][class_name], newBooking)
-				return newBooking, nil
+	for _, promoted := range waiting {
+		var members []structs.Member
+		if !promoted.CreditUsed {
+			members, err = p.charge(&promoted)
+			if errors.Is(err, ErrNoCredit) {
+				continue
+			}
+			if err != nil {
+				return storage.BookingChange{}, err
 			}
 		}
+		promoted.Status = structs.BookingStatusBooked
+		return storage.BookingChange{Update: []structs.Booking{promoted}, Members: members}, nil
+	}
+	return storage.BookingChange{}, nil
+}
+
+// promoteNext promotes the first member on the waitlist of the class session who can pay into
+// a spot given by a raised capacity, promoted is false when nobody can. Caller must hold p.classes.mu.
+func (p *BookingProcessor) promoteNext(className string, session time.Time) (promoted bool, err error) {
+
+	defer p.members.mu.Unlock()
+	p.members.mu.Lock()
+
+	change, err := p.promotion(className, session)
+	if err != nil || len(change.Update) == 0 {
+		return false, err
+	}
+	if _, err := p.bookings.ApplyChange(change); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	DateWiseoverallBookings[date][class_name] = append(DateWiseoverallBookings[date][class_name], newBooking)
-	return newBooking, nil
+// refund returns the member of the booking with the credit it used given back, to store with
+// the cancellation. Caller must hold p.members.mu.
+func (p *BookingProcessor) refund(booking structs.Booking) ([]structs.Member, error) {
+	if !booking.CreditUsed {
+		return nil, nil
+	}
+	member, refunded, err := p.members.withCreditRefunded(booking.MemberName, booking.ClassDate, booking.CreditAssignment)
+	if err != nil || !refunded {
+		return nil, err
+	}
+	return []structs.Member{member}, nil
 }
 
-// GetbookingsByDate function will return the total number of bookings
-// done on particular date
+// strike returns the registered member of the booking with one more strike, to store with the
+// late cancellation or no-show. Caller must hold p.members.mu.
+func (p *BookingProcessor) strike(booking structs.Booking) ([]structs.Member, error) {
+	member, registered, err := p.members.withStrike(booking.MemberName)
+	if err != nil || !registered {
+		return nil, err
+	}
+	return []structs.Member{member}, nil
+}
+
+// isLateCancel reports whether cancelling the booking now falls inside the cancellation
+// window of its class, caller must hold p.classes.mu
+func (p *BookingProcessor) isLateCancel(booking structs.Booking) (bool, error) {
+	class, err := p.classes.findScheduled(booking.ClassName, booking.ClassDate)
+	if errors.Is(err, ErrClassNotScheduled) {
+		return false, nil
+	}
+	if err != nil || class.CancellationPolicy == nil {
+		return false, err
+	}
+
+	window := time.Duration(class.CancellationPolicy.LateCancelHours) * time.Hour
+	return window > 0 && !p.now().Before(booking.ClassDate.Add(-window)), nil
+}
+
+// MarkNoShow records that the member of the booking did not attend the session, which
+// gives the member a strike
+// input booking id
+// output booking with status no_show, error
+func (p *BookingProcessor) MarkNoShow(id int) (structs.Booking, error) {
+
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	booking, err := p.bookings.GetBooking(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Booking{}, ErrBookingNotFound
+	}
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	if p.now().Before(booking.ClassDate) {
+		return structs.Booking{}, ErrSessionNotStarted
+	}
+
+	defer p.members.mu.Unlock()
+	p.members.mu.Lock()
+
+	struck, err := p.strike(booking)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	return p.recordAttendance(booking, structs.BookingStatusNoShow, struck...)
+}
+
+// CheckIn records that the member of the booking attended the session, check-ins are
+// accepted in the window of the booking rules around the start of the session
+// input booking id
+// output booking with status attended, error
+func (p *BookingProcessor) CheckIn(id int) (structs.Booking, error) {
+
+	defer p.classes.mu.Unlock()
+	p.classes.mu.Lock()
+
+	booking, err := p.bookings.GetBooking(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Booking{}, ErrBookingNotFound
+	}
+	if err != nil {
+		return structs.Booking{}, err
+	}
+
+	class, err := p.classes.findScheduled(booking.ClassName, booking.ClassDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	opens, closes := booking.ClassDate.Add(-p.rules.CheckInBefore), booking.ClassDate.Add(p.rules.CheckInAfter)
+	if class.Schedule == nil {
+		closes = sessionEnd(class, booking.ClassDate)
+	}
+	if now := p.now(); now.Before(opens) || !now.Before(closes) {
+		return structs.Booking{}, ErrCheckInClosed
+	}
+
+	return p.recordAttendance(booking, structs.BookingStatusAttended)
+}
+
+// GetRoster returns the confirmed bookings of the session of the class starting at session
+// with the check-in state of their members
+// input class id, session start
+// output roster, error
+func (p *BookingProcessor) GetRoster(id int, session time.Time) (structs.Roster, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	class, err := p.classes.getClass(id)
+	if err != nil {
+		return structs.Roster{}, err
+	}
+	scheduled, err := hasSession(class, session)
+	if err != nil {
+		return structs.Roster{}, err
+	}
+	if !scheduled {
+		return structs.Roster{}, ErrClassNotScheduled
+	}
+
+	bookings, err := p.bookings.ListBookings(class.ClassName, session)
+	if err != nil {
+		return structs.Roster{}, err
+	}
+
+	roster := structs.Roster{ClassID: class.ID, ClassName: class.ClassName, ClassDate: session, Capacity: class.Capacity, Members: []structs.RosterEntry{}}
+	for _, booking := range bookings {
+		roster.Members = append(roster.Members, structs.RosterEntry{
+			BookingID:  booking.ID,
+			MemberID:   booking.MemberID,
+			MemberName: booking.MemberName,
+			Status:     booking.Status,
+			CheckedIn:  booking.Status == structs.BookingStatusAttended,
+		})
+	}
+	return roster, nil
+}
+
+// recordAttendance changes the status of a confirmed booking without attendance to the
+// status, the members are stored with it. Caller must hold p.classes.mu.
+func (p *BookingProcessor) recordAttendance(booking structs.Booking, status string, members ...structs.Member) (structs.Booking, error) {
+	switch booking.Status {
+	case structs.BookingStatusWaitlisted:
+		return structs.Booking{}, ErrNotBooked
+	case structs.BookingStatusCancelled, structs.BookingStatusLateCancelled:
+		return structs.Booking{}, ErrBookingCancelled
+	case structs.BookingStatusBooked:
+	default:
+		return structs.Booking{}, ErrAttendanceRecorded
+	}
+
+	booking.Status = status
+	if _, err := p.bookings.ApplyChange(storage.BookingChange{Update: []structs.Booking{booking}, Members: members}); err != nil {
+		return structs.Booking{}, err
+	}
+	return booking, nil
+}
+
+// checkStrikes returns ErrTooManyStrikes when the member is registered and reached the
+// strikes of the booking rules, caller must hold p.classes.mu
+func (p *BookingProcessor) checkStrikes(member_name string) error {
+	if p.rules.MaxStrikes == 0 {
+		return nil
+	}
+
+	member, registered, err := p.members.findByName(member_name)
+	if err != nil {
+		return err
+	}
+	if registered && member.Strikes >= p.rules.MaxStrikes {
+		return ErrTooManyStrikes
+	}
+	return nil
+}
+
+// withWaitlistPosition sets the 1 based position of a waitlisted booking
+func (p *BookingProcessor) withWaitlistPosition(booking structs.Booking) (structs.Booking, error) {
+	if booking.Status != structs.BookingStatusWaitlisted {
+		return booking, nil
+	}
+
+	waiting, err := p.bookings.ListWaitlist(booking.ClassName, booking.ClassDate)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	for i, entry := range waiting {
+		if entry.ID == booking.ID {
+			booking.WaitlistPosition = i + 1
+			break
+		}
+	}
+	return booking, nil
+}
+
+// addEntry stores the booking or waitlist entry of the member with the status, a booking in
+// one step with the credit it uses. Caller must hold p.classes.mu.
+func (p *BookingProcessor) addEntry(class_name, member_name string, classDate time.Time, status string) (structs.Booking, error) {
+
+	defer p.members.mu.Unlock()
+	p.members.mu.Lock()
+
+	booking, err := p.newBooking(class_name, member_name, classDate, status)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	members, err := p.charge(&booking)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	//waitlisted members pay when they are promoted, joining only checks that they can pay
+	if status == structs.BookingStatusWaitlisted {
+		booking.CreditUsed, booking.CreditAssignment, members = false, 0, nil
+	}
+
+	booking, err = p.bookings.ApplyChange(storage.BookingChange{Add: &booking, Members: members})
+	if err != nil {
+		return structs.Booking{}, mapStorageError(err)
+	}
+	return booking, nil
+}
+
+// charge uses a credit of the member for the booking and returns the member to store with
+// it, nothing is returned when the booking is free. Caller must hold p.members.mu.
+func (p *BookingProcessor) charge(booking *structs.Booking) ([]structs.Member, error) {
+	member, used, err := p.members.withCreditUsed(booking.MemberName, booking.ClassDate, p.rules.RequireMembership)
+	if err != nil || !used {
+		return nil, err
+	}
+	booking.CreditUsed, booking.CreditAssignment = true, member.Membership.Assignment
+	return []structs.Member{member}, nil
+}
+
+// newBooking creates the booking of the member, a registered member is linked by id and
+// their registered name is used
+func (p *BookingProcessor) newBooking(class_name, member_name string, classDate time.Time, status string) (structs.Booking, error) {
+	booking := structs.Booking{MemberName: member_name, ClassDate: classDate, ClassName: class_name, Status: status}
+
+	member, registered, err := p.members.findByName(member_name)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	if registered {
+		booking.MemberID = member.ID
+		booking.MemberName = member.Name
+	}
+	return booking, nil
+}
+
+// mapStorageError reports a booking rejected by a uniqueness constraint of the store as ErrAlreadyBooked
+func mapStorageError(err error) error {
+	if errors.Is(err, storage.ErrConflict) {
+		return ErrAlreadyBooked
+	}
+	return err
+}
+
+// checkDuplicate returns ErrAlreadyBooked when the member already has a booking or a
+// waitlist entry for the class on the date, names are compared after normalization.
+// Caller must hold p.classes.mu.
+func (p *BookingProcessor) checkDuplicate(class_name, member_name string, classDate time.Time) error {
+	bookings, err := p.bookings.ListBookings(class_name, classDate)
+	if err != nil {
+		return err
+	}
+	waiting, err := p.bookings.ListWaitlist(class_name, classDate)
+	if err != nil {
+		return err
+	}
+
+	member := structs.NormalizeMemberName(member_name)
+	for _, booking := range append(bookings, waiting...) {
+		if structs.NormalizeMemberName(booking.MemberName) == member {
+			return ErrAlreadyBooked
+		}
+	}
+	return nil
+}
+
+// isClassFull reports whether the bookings for the class on the date have reached
+// its capacity, caller must hold p.classes.mu
+func (p *BookingProcessor) isClassFull(class structs.Class, classDate time.Time) (bool, error) {
+	bookings, err := p.bookings.ListBookings(class.ClassName, classDate)
+	if err != nil {
+		return false, err
+	}
+	return len(bookings) >= class.Capacity, nil
+}
+
+// GetBookedSessions returns the occupancy of the class sessions which start at or after from
+// and have bookings
+// input from time
+// output list of occupancies ordered by session start and class name, error
+func (p *BookingProcessor) GetBookedSessions(from time.Time) ([]structs.Occupancy, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	classes, err := p.classes.classes.ListClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []structs.Occupancy{}
+	for _, class := range classes {
+		bookings, err := p.classes.classBookings(class)
+		if err != nil {
+			return nil, err
+		}
+
+		//sessions are keyed by their instant, the same session may be stored in another location
+		sessions := make(map[int64]time.Time)
+		booked := make(map[int64]int)
+		for _, booking := range bookings {
+			if !booking.ClassDate.Before(from) {
+				sessions[booking.ClassDate.Unix()] = booking.ClassDate
+				booked[booking.ClassDate.Unix()]++
+			}
+		}
+		for key, session := range sessions {
+			waiting, err := p.bookings.ListWaitlist(class.ClassName, session)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, newOccupancy(class, session, booked[key], len(waiting)))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].ClassDate.Equal(result[j].ClassDate) {
+			return result[i].ClassDate.Before(result[j].ClassDate)
+		}
+		return result[i].ClassName < result[j].ClassName
+	})
+	return result, nil
+}
+
+// GetClassOccupancy returns the occupancy of every session of the class on the dates from-to,
+// zero dates default to the start and end date of the class
+// input class id, from, to
+// output list of occupancies ordered by session start, error
+func (p *BookingProcessor) GetClassOccupancy(id int, from, to time.Time) ([]structs.Occupancy, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	class, err := p.classes.getClass(id)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() {
+		from = class.StartDate
+	}
+	if to.IsZero() {
+		to = class.EndDate
+	}
+	return p.classOccupancy(class, from, to)
+}
+
+// GetOccupancyByDate returns the occupancy of every class session on the date, sessions
+// without bookings included
 // input classdate
-// output list of bookings
-func GetBookingsByDate(classDate time.Time) (map[string][]structs.Booking, error) {
+// output list of occupancies ordered by session start and class name, error
+func (p *BookingProcessor) GetOccupancyByDate(classDate time.Time) ([]structs.Occupancy, error) {
+
+	defer p.classes.mu.RUnlock()
+	p.classes.mu.RLock()
+
+	classes, err := p.classes.classes.ListClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []structs.Occupancy{}
+	for _, class := range classes {
+		occupancy, err := p.classOccupancy(class, classDate, classDate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, occupancy...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].ClassDate.Equal(result[j].ClassDate) {
+			return result[i].ClassDate.Before(result[j].ClassDate)
+		}
+		return result[i].ClassName < result[j].ClassName
+	})
+	return result, nil
+}
+
+// classOccupancy counts the bookings and waitlist of every session of the class on the
+// dates from-to, caller must hold p.classes.mu
+func (p *BookingProcessor) classOccupancy(class structs.Class, from, to time.Time) ([]structs.Occupancy, error) {
+	sessions, err := sessionsBetween(class, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]structs.Occupancy, 0, len(sessions))
+	for _, session := range sessions {
+		bookings, err := p.bookings.ListBookings(class.ClassName, session)
+		if err != nil {
+			return nil, err
+		}
+		waiting, err := p.bookings.ListWaitlist(class.ClassName, session)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newOccupancy(class, session, len(bookings), len(waiting)))
+	}
+	return result, nil
+}
 
-	date := classDate.Format(DATEFORMAT)
-	//check whether anybookings are there
-	if len(DateWiseoverallBookings[date]) == 0 {
-		return nil, errors.New("no bookings available for the selected date")
+// newOccupancy returns the occupancy of the session of the class
+func newOccupancy(class structs.Class, session time.Time, booked, waitlisted int) structs.Occupancy {
+	return structs.Occupancy{
+		ClassID:    class.ID,
+		ClassName:  class.ClassName,
+		ClassDate:  session,
+		Capacity:   class.Capacity,
+		Booked:     booked,
+		Waitlisted: waitlisted,
+		Remaining:  max(class.Capacity-booked, 0),
 	}
-	return DateWiseoverallBookings[date], nil
 }
@@ -3,15 +3,25 @@ package processors
 import (
 	"testing"
 	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 )
 
 func TestBookClass(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
 	memberName := "Sai Kumar"
 	ClassName := "Yoga"
 	classDate, _ := time.Parse("2006-01-02", "2025-02-22")
+	if _, err := classProcessor.CreateClass(ClassName, classDate, classDate, 10, nil, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
 
 	// Create booking
-	booking, err := BookClass(ClassName, memberName, classDate)
+	booking, err := bookingProcessor.BookClass(ClassName, memberName, classDate)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -24,3 +34,544 @@ func TestBookClass(t *testing.T) {
 		t.Fatalf("expected class date %v, got %v", classDate, booking.ClassDate)
 	}
 }
+
+func TestBookClass_ClassNotScheduled(t *testing.T) {
+	store := storage.NewMemoryStore()
+	bookingProcessor := NewBookingProcessor(NewClassProcessor(store, store, store), NewMemberProcessor(store), store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	if _, err := bookingProcessor.BookClass("kickboxing", "Sai Kumar", classDate); err != ErrClassNotScheduled {
+		t.Fatalf("expected %v, got %v", ErrClassNotScheduled, err)
+	}
+
+	if _, err := bookingProcessor.JoinWaitlist("kickboxing", "Sai Kumar", classDate); err != ErrClassNotScheduled {
+		t.Fatalf("expected %v, got %v", ErrClassNotScheduled, err)
+	}
+}
+
+func TestBookClass_ClassFull(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-02")
+	if _, err := classProcessor.CreateClass("spin", startDate, endDate, 1, nil, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := bookingProcessor.BookClass("spin", "Sai Kumar", startDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := bookingProcessor.BookClass("spin", "John", startDate); err != ErrClassFull {
+		t.Fatalf("expected %v, got %v", ErrClassFull, err)
+	}
+
+	//capacity is tracked per date, so the next day still has a spot
+	if _, err := bookingProcessor.BookClass("spin", "John", endDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCancelBooking_PromotesWaitlist(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	if _, err := classProcessor.CreateClass("barre", classDate, classDate, 1, nil, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := bookingProcessor.JoinWaitlist("barre", "Sai Kumar", classDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	first, _ := bookingProcessor.JoinWaitlist("barre", "John", classDate)
+	second, _ := bookingProcessor.JoinWaitlist("barre", "Jane", classDate)
+	if first.Status != structs.BookingStatusWaitlisted || first.WaitlistPosition != 1 || second.WaitlistPosition != 2 {
+		t.Fatalf("expected waitlist positions 1 and 2, got %d and %d", first.WaitlistPosition, second.WaitlistPosition)
+	}
+
+	if _, err := bookingProcessor.CancelBooking("barre", "Sai Kumar", classDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bookings, _ := store.ListBookings("barre", classDate)
+	if len(bookings) != 1 || bookings[0].MemberName != "John" || bookings[0].Status != structs.BookingStatusBooked {
+		t.Fatalf("expected John to be promoted from the waitlist, got %v", bookings)
+	}
+
+	if waiting, _ := store.ListWaitlist("barre", classDate); len(waiting) != 1 || waiting[0].MemberName != "Jane" {
+		t.Fatalf("expected Jane to remain on the waitlist, got %v", waiting)
+	}
+}
+
+func TestCancelBookingByID(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	classProcessor.CreateClass("barre", classDate, classDate, 1, nil, nil, 0, 0)
+
+	booked, _ := bookingProcessor.BookClass("barre", "Sai Kumar", classDate)
+	waiting, _ := bookingProcessor.JoinWaitlist("barre", "John", classDate)
+	if booked.ID == 0 || waiting.ID == 0 || booked.ID == waiting.ID {
+		t.Fatalf("expected unique booking ids, got %d and %d", booked.ID, waiting.ID)
+	}
+
+	found, err := bookingProcessor.GetBooking(waiting.ID)
+	if err != nil || found.WaitlistPosition != 1 {
+		t.Fatalf("expected waitlist position 1, got %v, %v", found, err)
+	}
+
+	cancelled, err := bookingProcessor.CancelBookingByID(booked.ID)
+	if err != nil || cancelled.Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected cancelled booking, got %v, %v", cancelled, err)
+	}
+
+	// The waitlisted booking keeps its id once promoted
+	promoted, err := bookingProcessor.GetBooking(waiting.ID)
+	if err != nil || promoted.Status != structs.BookingStatusBooked || promoted.WaitlistPosition != 0 {
+		t.Fatalf("expected booking %d to be promoted, got %v, %v", waiting.ID, promoted, err)
+	}
+
+	if _, err := bookingProcessor.CancelBookingByID(booked.ID); err != ErrBookingCancelled {
+		t.Fatalf("expected %v, got %v", ErrBookingCancelled, err)
+	}
+
+	// The cancelled booking stays in the history of the member
+	bookings, _ := bookingProcessor.GetMemberBookings("Sai Kumar")
+	if len(bookings) != 1 || bookings[0].ID != booked.ID || bookings[0].Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected the cancelled booking of Sai Kumar, got %v", bookings)
+	}
+}
+
+func TestCancelBooking_KeepsHistory(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	classProcessor.CreateClass("barre", classDate, classDate, 1, nil, &structs.CancellationPolicy{LateCancelHours: 24}, 0, 0)
+
+	booked, _ := bookingProcessor.BookClass("barre", "Sai Kumar", classDate)
+	bookingProcessor.now = func() time.Time { return classDate.Add(-time.Hour) }
+	if _, err := bookingProcessor.CancelBookingByID(booked.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// A late cancelled booking is read back with its status
+	found, err := bookingProcessor.GetBooking(booked.ID)
+	if err != nil || found.Status != structs.BookingStatusLateCancelled {
+		t.Fatalf("expected status %s, got %v, %v", structs.BookingStatusLateCancelled, found, err)
+	}
+
+	// It holds no spot, so the member can book the session again
+	occupancy, _ := bookingProcessor.GetOccupancyByDate(classDate)
+	if len(occupancy) != 1 || occupancy[0].Booked != 0 {
+		t.Fatalf("expected no booked spots, got %v", occupancy)
+	}
+	rebooked, err := bookingProcessor.BookClass("barre", "Sai Kumar", classDate)
+	if err != nil || rebooked.ID == booked.ID {
+		t.Fatalf("expected a new booking, got %v, %v", rebooked, err)
+	}
+	bookingProcessor.now = func() time.Time { return classDate.Add(time.Hour) }
+	if _, err := bookingProcessor.MarkNoShow(booked.ID); err != ErrBookingCancelled {
+		t.Fatalf("expected %v, got %v", ErrBookingCancelled, err)
+	}
+}
+
+func TestBookClass_Duplicate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-15")
+	classProcessor.CreateClass("yoga", classDate, classDate, 1, nil, nil, 0, 0)
+
+	if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"Sai Kumar", "  sai kumar ", "SAI KUMAR"} {
+		if _, err := bookingProcessor.BookClass("yoga", name, classDate); err != ErrAlreadyBooked {
+			t.Fatalf("expected %v for %q, got %v", ErrAlreadyBooked, name, err)
+		}
+		if _, err := bookingProcessor.JoinWaitlist("yoga", name, classDate); err != ErrAlreadyBooked {
+			t.Fatalf("expected %v for %q, got %v", ErrAlreadyBooked, name, err)
+		}
+	}
+
+	// A waitlisted member cannot book or join the waitlist again either
+	if _, err := bookingProcessor.JoinWaitlist("yoga", "John", classDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := bookingProcessor.JoinWaitlist("yoga", "john", classDate); err != ErrAlreadyBooked {
+		t.Fatalf("expected %v, got %v", ErrAlreadyBooked, err)
+	}
+
+	if bookings, _ := bookingProcessor.GetMemberBookings(" SAI kumar"); len(bookings) != 1 {
+		t.Fatalf("expected one booking for Sai Kumar, got %v", bookings)
+	}
+}
+
+func TestBookClass_Session(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	// 2025-03-03 is a Monday
+	monday, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{Weekdays: []string{"mon"}, StartTimes: []string{"07:00", "18:30"}, Duration: 60}
+	classProcessor.CreateClass("yoga", monday, monday.AddDate(0, 0, 13), 1, schedule, nil, 0, 0)
+
+	morning := monday.Add(7 * time.Hour)
+	evening := monday.Add(18*time.Hour + 30*time.Minute)
+	for _, session := range []time.Time{monday, morning.Add(time.Hour), morning.AddDate(0, 0, 1)} {
+		if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", session); err != ErrClassNotScheduled {
+			t.Fatalf("expected %v for %s, got %v", ErrClassNotScheduled, session, err)
+		}
+	}
+
+	booking, err := bookingProcessor.BookClass("yoga", "Sai Kumar", morning)
+	if err != nil || !booking.ClassDate.Equal(morning) {
+		t.Fatalf("expected booking for %s, got %v, %v", morning, booking, err)
+	}
+
+	// Capacity and duplicates are per session
+	if _, err := bookingProcessor.BookClass("yoga", "John", morning); err != ErrClassFull {
+		t.Fatalf("expected %v, got %v", ErrClassFull, err)
+	}
+	if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", evening); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGetBookedSessions(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	classProcessor.CreateClass("spin", startDate, endDate, 1, nil, nil, 0, 0)
+	classProcessor.CreateClass("yoga", startDate, endDate, 5, nil, nil, 0, 0)
+
+	bookingProcessor.BookClass("spin", "Sai Kumar", startDate)
+	bookingProcessor.BookClass("spin", "Sai Kumar", endDate)
+	bookingProcessor.JoinWaitlist("spin", "John", endDate)
+	bookingProcessor.BookClass("yoga", "John", endDate)
+
+	// Sessions before the from date and sessions without bookings are left out
+	sessions, err := bookingProcessor.GetBookedSessions(startDate.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []structs.Occupancy{
+		{ClassID: 1, ClassName: "spin", ClassDate: endDate, Capacity: 1, Booked: 1, Waitlisted: 1, Remaining: 0},
+		{ClassID: 2, ClassName: "yoga", ClassDate: endDate, Capacity: 5, Booked: 1, Waitlisted: 0, Remaining: 4},
+	}
+	if len(sessions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, sessions)
+	}
+	for i := range expected {
+		if sessions[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], sessions[i])
+		}
+	}
+}
+
+func TestGetClassOccupancy(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	// 2025-03-03 is a Monday
+	monday, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{Weekdays: []string{"mon", "wed"}, StartTimes: []string{"07:00"}, Duration: 60}
+	class, _ := classProcessor.CreateClass("yoga", monday, monday.AddDate(0, 0, 13), 1, schedule, nil, 0, 0)
+
+	morning := monday.Add(7 * time.Hour)
+	bookingProcessor.BookClass("yoga", "Sai Kumar", morning)
+	bookingProcessor.JoinWaitlist("yoga", "John", morning)
+
+	// Zero dates cover the whole class, sessions without bookings are included
+	sessions, err := bookingProcessor.GetClassOccupancy(class.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 4 {
+		t.Fatalf("expected 4 sessions, got %v", sessions)
+	}
+	expected := structs.Occupancy{ClassID: class.ID, ClassName: "yoga", ClassDate: morning, Capacity: 1, Booked: 1, Waitlisted: 1, Remaining: 0}
+	if sessions[0] != expected {
+		t.Errorf("expected %v, got %v", expected, sessions[0])
+	}
+	expected = structs.Occupancy{ClassID: class.ID, ClassName: "yoga", ClassDate: morning.AddDate(0, 0, 2), Capacity: 1, Remaining: 1}
+	if sessions[1] != expected {
+		t.Errorf("expected %v, got %v", expected, sessions[1])
+	}
+
+	sessions, err = bookingProcessor.GetClassOccupancy(class.ID, monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 30))
+	if err != nil || len(sessions) != 2 || !sessions[0].ClassDate.Equal(morning.AddDate(0, 0, 7)) {
+		t.Fatalf("expected the 2 sessions of the second week, got %v, %v", sessions, err)
+	}
+
+	if _, err := bookingProcessor.GetClassOccupancy(99, time.Time{}, time.Time{}); err != ErrClassNotFound {
+		t.Fatalf("expected %v, got %v", ErrClassNotFound, err)
+	}
+}
+
+func TestGetOccupancyByDate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	classProcessor.CreateClass("yoga", startDate, endDate, 5, nil, nil, 0, 0)
+	classProcessor.CreateClass("spin", startDate, startDate, 2, nil, nil, 0, 0)
+	bookingProcessor.BookClass("yoga", "Sai Kumar", startDate)
+
+	sessions, err := bookingProcessor.GetOccupancyByDate(startDate)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []structs.Occupancy{
+		{ClassID: 2, ClassName: "spin", ClassDate: startDate, Capacity: 2, Remaining: 2},
+		{ClassID: 1, ClassName: "yoga", ClassDate: startDate, Capacity: 5, Booked: 1, Remaining: 4},
+	}
+	if len(sessions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, sessions)
+	}
+	for i := range expected {
+		if sessions[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], sessions[i])
+		}
+	}
+
+	// Dates without sessions have an empty occupancy
+	sessions, err = bookingProcessor.GetOccupancyByDate(endDate.AddDate(0, 0, 1))
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("expected no sessions, got %v, %v", sessions, err)
+	}
+}
+
+func TestCancelBooking_Policy(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	// 2025-03-03 is a Monday
+	monday, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{StartTimes: []string{"18:00"}, Duration: 60}
+	classProcessor.CreateClass("yoga", monday, monday.AddDate(0, 0, 6), 1, schedule, &structs.CancellationPolicy{LateCancelHours: 12}, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+
+	session := monday.Add(18 * time.Hour)
+	bookingProcessor.now = func() time.Time { return session.Add(-13 * time.Hour) }
+
+	// Cancelling before the window is free
+	bookingProcessor.BookClass("yoga", "Sai Kumar", session)
+	cancelled, err := bookingProcessor.CancelBooking("yoga", "Sai Kumar", session)
+	if err != nil || cancelled.Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected status %s, got %v, %v", structs.BookingStatusCancelled, cancelled, err)
+	}
+
+	// Cancelling inside the window is late and gives a strike, the waitlist is still promoted
+	booking, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", session)
+	bookingProcessor.JoinWaitlist("yoga", "John", session)
+	bookingProcessor.now = func() time.Time { return session.Add(-time.Hour) }
+	cancelled, err = bookingProcessor.CancelBookingByID(booking.ID)
+	if err != nil || cancelled.Status != structs.BookingStatusLateCancelled {
+		t.Fatalf("expected status %s, got %v, %v", structs.BookingStatusLateCancelled, cancelled, err)
+	}
+	if found, _ := memberProcessor.GetMember(member.ID); found.Strikes != 1 {
+		t.Fatalf("expected 1 strike, got %v", found)
+	}
+	if bookings, _ := store.ListBookings("yoga", session); len(bookings) != 1 || bookings[0].MemberName != "John" {
+		t.Fatalf("expected John to be promoted, got %v", bookings)
+	}
+
+	// Waitlist entries never count as late
+	next := session.AddDate(0, 0, 1)
+	bookingProcessor.BookClass("yoga", "John", next)
+	bookingProcessor.JoinWaitlist("yoga", "Sai Kumar", next)
+	bookingProcessor.now = func() time.Time { return next.Add(-time.Hour) }
+	if cancelled, _ := bookingProcessor.CancelBooking("yoga", "Sai Kumar", next); cancelled.Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected status %s, got %v", structs.BookingStatusCancelled, cancelled)
+	}
+	if found, _ := memberProcessor.GetMember(member.ID); found.Strikes != 1 {
+		t.Fatalf("expected 1 strike, got %v", found)
+	}
+}
+
+func TestMarkNoShow(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	classProcessor.CreateClass("spin", classDate, classDate, 1, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	booking, _ := bookingProcessor.BookClass("spin", "Sai Kumar", classDate)
+	waiting, _ := bookingProcessor.JoinWaitlist("spin", "John", classDate)
+
+	bookingProcessor.now = func() time.Time { return classDate.Add(-time.Minute) }
+	if _, err := bookingProcessor.MarkNoShow(booking.ID); err != ErrSessionNotStarted {
+		t.Fatalf("expected %v, got %v", ErrSessionNotStarted, err)
+	}
+
+	bookingProcessor.now = func() time.Time { return classDate.Add(time.Hour) }
+	noShow, err := bookingProcessor.MarkNoShow(booking.ID)
+	if err != nil || noShow.Status != structs.BookingStatusNoShow {
+		t.Fatalf("expected status %s, got %v, %v", structs.BookingStatusNoShow, noShow, err)
+	}
+	if found, _ := memberProcessor.GetMember(member.ID); found.Strikes != 1 {
+		t.Fatalf("expected 1 strike, got %v", found)
+	}
+
+	// The no-show keeps the spot and cannot be recorded or cancelled again
+	if _, err := bookingProcessor.MarkNoShow(booking.ID); err != ErrAttendanceRecorded {
+		t.Fatalf("expected %v, got %v", ErrAttendanceRecorded, err)
+	}
+	if _, err := bookingProcessor.CancelBookingByID(booking.ID); err != ErrAttendanceRecorded {
+		t.Fatalf("expected %v, got %v", ErrAttendanceRecorded, err)
+	}
+	if _, err := bookingProcessor.MarkNoShow(waiting.ID); err != ErrNotBooked {
+		t.Fatalf("expected %v, got %v", ErrNotBooked, err)
+	}
+	if _, err := bookingProcessor.MarkNoShow(99); err != ErrBookingNotFound {
+		t.Fatalf("expected %v, got %v", ErrBookingNotFound, err)
+	}
+}
+
+func TestBookClass_TooManyStrikes(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+	bookingProcessor.SetRules(BookingRules{MaxStrikes: 2})
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	classProcessor.CreateClass("spin", startDate, endDate, 1, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	bookingProcessor.now = func() time.Time { return endDate.AddDate(0, 0, 1) }
+
+	for _, day := range []int{0, 1} {
+		booking, err := bookingProcessor.BookClass("spin", "Sai Kumar", startDate.AddDate(0, 0, day))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		bookingProcessor.MarkNoShow(booking.ID)
+	}
+
+	if _, err := bookingProcessor.BookClass("spin", "sai kumar", endDate); err != ErrTooManyStrikes {
+		t.Fatalf("expected %v, got %v", ErrTooManyStrikes, err)
+	}
+	if _, err := bookingProcessor.JoinWaitlist("spin", "Sai Kumar", endDate); err != ErrTooManyStrikes {
+		t.Fatalf("expected %v, got %v", ErrTooManyStrikes, err)
+	}
+
+	// Resetting the strikes lets the member book again
+	if reset, err := memberProcessor.ResetStrikes(member.ID); err != nil || reset.Strikes != 0 {
+		t.Fatalf("expected no strikes, got %v, %v", reset, err)
+	}
+	if _, err := bookingProcessor.BookClass("spin", "Sai Kumar", endDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	bookingProcessor.SetRules(BookingRules{CheckInBefore: 30 * time.Minute, CheckInAfter: 15 * time.Minute})
+
+	// 2025-03-03 is a Monday
+	monday, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{StartTimes: []string{"18:00"}, Duration: 60}
+	classProcessor.CreateClass("yoga", monday, monday, 1, schedule, nil, 0, 0)
+	classProcessor.CreateClass("spin", monday, monday, 1, nil, nil, 0, 0)
+
+	session := monday.Add(18 * time.Hour)
+	booking, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", session)
+	waiting, _ := bookingProcessor.JoinWaitlist("yoga", "John", session)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		err  error
+	}{
+		{"before the window", session.Add(-31 * time.Minute), ErrCheckInClosed},
+		{"after the window", session.Add(15 * time.Minute), ErrCheckInClosed},
+		{"inside the window", session.Add(-30 * time.Minute), nil},
+		{"again", session, ErrAttendanceRecorded},
+	}
+	for _, test := range tests {
+		bookingProcessor.now = func() time.Time { return test.now }
+		checkedIn, err := bookingProcessor.CheckIn(booking.ID)
+		if err != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+		if err == nil && checkedIn.Status != structs.BookingStatusAttended {
+			t.Fatalf("%s: expected status %s, got %v", test.name, structs.BookingStatusAttended, checkedIn)
+		}
+	}
+
+	if _, err := bookingProcessor.CheckIn(waiting.ID); err != ErrNotBooked {
+		t.Fatalf("expected %v, got %v", ErrNotBooked, err)
+	}
+	if _, err := bookingProcessor.CheckIn(99); err != ErrBookingNotFound {
+		t.Fatalf("expected %v, got %v", ErrBookingNotFound, err)
+	}
+
+	// All day sessions accept check-ins until the end of the day
+	allDay, _ := bookingProcessor.BookClass("spin", "Sai Kumar", monday)
+	bookingProcessor.now = func() time.Time { return monday.Add(23 * time.Hour) }
+	if _, err := bookingProcessor.CheckIn(allDay.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGetRoster(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	bookingProcessor.SetRules(BookingRules{CheckInBefore: time.Hour, CheckInAfter: time.Hour})
+
+	// 2025-03-03 is a Monday
+	monday, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{StartTimes: []string{"07:00", "18:00"}, Duration: 60}
+	class, _ := classProcessor.CreateClass("yoga", monday, monday, 2, schedule, nil, 0, 0)
+
+	session := monday.Add(7 * time.Hour)
+	first, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", session)
+	bookingProcessor.BookClass("yoga", "John", session)
+	bookingProcessor.BookClass("yoga", "Jane", session.Add(11*time.Hour))
+
+	bookingProcessor.now = func() time.Time { return session }
+	bookingProcessor.CheckIn(first.ID)
+
+	roster, err := bookingProcessor.GetRoster(class.ID, session)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if roster.ClassID != class.ID || !roster.ClassDate.Equal(session) || roster.Capacity != 2 || len(roster.Members) != 2 {
+		t.Fatalf("expected the 2 members of the morning session, got %v", roster)
+	}
+	if !roster.Members[0].CheckedIn || roster.Members[0].MemberName != "Sai Kumar" || roster.Members[1].CheckedIn {
+		t.Fatalf("expected only Sai Kumar to be checked in, got %v", roster.Members)
+	}
+
+	if _, err := bookingProcessor.GetRoster(class.ID, monday); err != ErrClassNotScheduled {
+		t.Fatalf("expected %v, got %v", ErrClassNotScheduled, err)
+	}
+	if _, err := bookingProcessor.GetRoster(99, session); err != ErrClassNotFound {
+		t.Fatalf("expected %v, got %v", ErrClassNotFound, err)
+	}
+}
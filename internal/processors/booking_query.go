@@ -0,0 +1,142 @@
+package processors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// Sort orders of ListBookings, a leading - reverses the order. Bookings with the same
+// value are ordered by id.
+const (
+	SortByClassDate = "class_date"
+	SortByClassName = "class_name"
+	SortByID        = "id"
+)
+
+// DefaultPageSize is the number of bookings of a page when the query sets no limit
+const DefaultPageSize = 50
+
+// MaxPageSize is the largest number of bookings of a page
+const MaxPageSize = 100
+
+// ErrInvalidCursor is returned for a cursor which is not the next cursor of a page with the same sort order
+var ErrInvalidCursor = &Error{Code: "invalid_cursor", Kind: KindInvalid, Message: "cursor is not valid for the query"}
+
+// BookingQuery filters, sorts and pages the bookings returned by ListBookings
+type BookingQuery struct {
+	From       time.Time //first date of the bookings, zero leaves the range open
+	To         time.Time //last date of the bookings, zero leaves the range open
+	ClassName  string
+	MemberName string
+	Sort       string //SortByClassDate when empty
+	Limit      int    //DefaultPageSize when zero
+	Cursor     string //NextCursor of the previous page, empty for the first page
+}
+
+// cursor is the position after the last booking of a page. The page continues after the
+// booking's sort value rather than at an offset, so bookings made or cancelled meanwhile
+// never shift bookings between pages.
+type cursor struct {
+	Sort      string `json:"s"`
+	ID        int    `json:"i"`
+	ClassDate int64  `json:"d,omitempty"`
+	ClassName string `json:"n,omitempty"`
+}
+
+// ListBookings returns a page of the confirmed bookings matching the query
+// input booking query
+// output page of bookings with the cursor of the next page, error
+func (p *BookingProcessor) ListBookings(query BookingQuery) (structs.BookingPage, error) {
+	if query.Sort == "" {
+		query.Sort = SortByClassDate
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultPageSize
+	}
+	field, descending := strings.TrimPrefix(query.Sort, "-"), strings.HasPrefix(query.Sort, "-")
+
+	var after *structs.Booking
+	if query.Cursor != "" {
+		position, err := decodeCursor(query.Cursor)
+		if err != nil || position.Sort != query.Sort {
+			return structs.BookingPage{}, ErrInvalidCursor
+		}
+		after = &structs.Booking{ID: position.ID, ClassDate: time.Unix(position.ClassDate, 0), ClassName: position.ClassName}
+	}
+
+	p.classes.mu.RLock()
+	bookings, err := p.bookings.QueryBookings(storage.BookingFilter{
+		From:       query.From,
+		To:         query.To,
+		ClassName:  query.ClassName,
+		MemberName: query.MemberName,
+	})
+	p.classes.mu.RUnlock()
+	if err != nil {
+		return structs.BookingPage{}, err
+	}
+
+	less := func(a, b structs.Booking) bool {
+		order := compareBookings(a, b, field)
+		if descending {
+			order = -order
+		}
+		return order < 0
+	}
+	sort.Slice(bookings, func(i, j int) bool { return less(bookings[i], bookings[j]) })
+
+	//the page starts at the first booking after the cursor
+	start := 0
+	if after != nil {
+		start = sort.Search(len(bookings), func(i int) bool { return less(*after, bookings[i]) })
+	}
+
+	page := structs.BookingPage{Bookings: []structs.Booking{}}
+	end := min(start+query.Limit, len(bookings))
+	page.Bookings = append(page.Bookings, bookings[start:end]...)
+	if end < len(bookings) {
+		last := bookings[end-1]
+		page.NextCursor = encodeCursor(cursor{Sort: query.Sort, ID: last.ID, ClassDate: last.ClassDate.Unix(), ClassName: last.ClassName})
+	}
+	return page, nil
+}
+
+// compareBookings orders the bookings by the field and then by id
+func compareBookings(a, b structs.Booking, field string) int {
+	switch field {
+	case SortByClassDate:
+		if !a.ClassDate.Equal(b.ClassDate) {
+			if a.ClassDate.Before(b.ClassDate) {
+				return -1
+			}
+			return 1
+		}
+	case SortByClassName:
+		if order := strings.Compare(a.ClassName, b.ClassName); order != 0 {
+			return order
+		}
+	}
+	return a.ID - b.ID
+}
+
+// encodeCursor encodes the position as an opaque token
+func encodeCursor(position cursor) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a token of encodeCursor
+func decodeCursor(token string) (cursor, error) {
+	var position cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &position)
+	}
+	return position, err
+}
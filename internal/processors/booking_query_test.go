@@ -0,0 +1,155 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// newTestBookingQuery creates a booking processor with a yoga and a spin class on the first
+// days of March 2025, each booked by the members on every day
+func newTestBookingQuery(t *testing.T, members ...string) (*BookingProcessor, time.Time) {
+	t.Helper()
+
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate := startDate.AddDate(0, 0, 2)
+	for _, className := range []string{"yoga", "spin"} {
+		if _, err := classProcessor.CreateClass(className, startDate, endDate, 10, nil, nil, 0, 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+			for _, member := range members {
+				if _, err := bookingProcessor.BookClass(className, member, date); err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			}
+		}
+	}
+	return bookingProcessor, startDate
+}
+
+// bookingIDs returns the ids of the bookings
+func bookingIDs(bookings []structs.Booking) []int {
+	ids := make([]int, len(bookings))
+	for i, booking := range bookings {
+		ids[i] = booking.ID
+	}
+	return ids
+}
+
+func TestListBookings_Filters(t *testing.T) {
+	bookingProcessor, startDate := newTestBookingQuery(t, "Sai Kumar", "John")
+
+	tests := []struct {
+		name  string
+		query BookingQuery
+		count int
+	}{
+		{"every booking", BookingQuery{}, 12},
+		{"from date", BookingQuery{From: startDate.AddDate(0, 0, 1)}, 8},
+		{"date range", BookingQuery{From: startDate.AddDate(0, 0, 1), To: startDate.AddDate(0, 0, 1)}, 4},
+		{"class", BookingQuery{ClassName: "spin"}, 6},
+		{"member", BookingQuery{MemberName: " sai kumar "}, 6},
+		{"every filter", BookingQuery{From: startDate, To: startDate, ClassName: "yoga", MemberName: "John"}, 1},
+		{"no match", BookingQuery{MemberName: "Jane"}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := bookingProcessor.ListBookings(test.query)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(page.Bookings) != test.count || page.NextCursor != "" {
+				t.Fatalf("expected %d bookings on a single page, got %v", test.count, page)
+			}
+		})
+	}
+}
+
+func TestListBookings_Pages(t *testing.T) {
+	bookingProcessor, _ := newTestBookingQuery(t, "Sai Kumar", "John")
+
+	for _, sort := range []string{SortByClassDate, "-" + SortByClassDate, SortByClassName, "-" + SortByID} {
+		t.Run(sort, func(t *testing.T) {
+			all, _ := bookingProcessor.ListBookings(BookingQuery{Sort: sort})
+
+			// Walking the pages returns every booking once in the same order
+			var walked []structs.Booking
+			query := BookingQuery{Sort: sort, Limit: 5}
+			for pages := 0; ; pages++ {
+				page, err := bookingProcessor.ListBookings(query)
+				if err != nil || pages > 3 {
+					t.Fatalf("expected 3 pages, got %d pages, %v", pages, err)
+				}
+				walked = append(walked, page.Bookings...)
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			if got, want := bookingIDs(walked), bookingIDs(all.Bookings); len(got) != 12 || !equalInts(got, want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+
+	// Sorted by class name the spin bookings come first, each class by date and then id
+	page, _ := bookingProcessor.ListBookings(BookingQuery{Sort: SortByClassName, Limit: 1})
+	if page.Bookings[0].ClassName != "spin" {
+		t.Fatalf("expected a spin booking first, got %v", page.Bookings[0])
+	}
+}
+
+func TestListBookings_StableWhileBooking(t *testing.T) {
+	bookingProcessor, startDate := newTestBookingQuery(t, "Sai Kumar", "John")
+
+	// Newest bookings first, the first page holds bookings 12 to 9
+	query := BookingQuery{Sort: "-" + SortByID, Limit: 4}
+	first, _ := bookingProcessor.ListBookings(query)
+
+	// A booking sorted into the first page and a cancelled booking of it do not shift the next page
+	bookingProcessor.BookClass("yoga", "Jane", startDate)
+	bookingProcessor.CancelBookingByID(first.Bookings[0].ID)
+
+	query.Cursor = first.NextCursor
+	second, err := bookingProcessor.ListBookings(query)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got, want := bookingIDs(second.Bookings), []int{8, 7, 6, 5}; !equalInts(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestListBookings_InvalidCursor(t *testing.T) {
+	bookingProcessor, _ := newTestBookingQuery(t, "Sai Kumar")
+
+	page, _ := bookingProcessor.ListBookings(BookingQuery{Limit: 1})
+	for _, query := range []BookingQuery{
+		{Cursor: "not a cursor"},
+		{Cursor: page.NextCursor, Sort: "-" + SortByClassDate},
+	} {
+		if _, err := bookingProcessor.ListBookings(query); err != ErrInvalidCursor {
+			t.Errorf("expected %v, got %v", ErrInvalidCursor, err)
+		}
+	}
+}
+
+// equalInts reports whether the slices hold the same ints in the same order
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,44 +2,383 @@ package processors
 
 import (
 	"errors"
-	"sync/atomic"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
 	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 )
 
-var classes []structs.Class
-var classID int64 = 1
+var (
+	// ErrClassConflict is returned when the class sessions overlap an existing class with the same name,
+	// instructor or room, it is wrapped with the clashing class
+	ErrClassConflict         = &Error{Code: "class_conflict", Kind: KindConflict, Message: "class date conflicts with existing class schedule"}
+	ErrClassNotFound         = &Error{Code: "class_not_found", Kind: KindNotFound, Message: "class not found"}
+	ErrInvalidClassDates     = &Error{Code: "invalid_class_dates", Kind: KindInvalid, Message: "startDate cannot be greater than endDate"}
+	ErrBookingsOutsideDates  = &Error{Code: "bookings_outside_dates", Kind: KindConflict, Message: "class has bookings on dates outside the new schedule"}
+	ErrCapacityBelowBookings = &Error{Code: "capacity_below_bookings", Kind: KindConflict, Message: "capacity is below the number of bookings on a class session"}
+	ErrClassHasBookings      = &Error{Code: "class_has_bookings", Kind: KindConflict, Message: "class cannot be deleted while it has bookings"}
+	// ErrClassInPast is returned when the start or end date of a class is before today in the studio's timezone
+	ErrClassInPast = &Error{Code: "class_in_past", Kind: KindInvalid, Message: "class dates cannot be past dates"}
+)
+
+// ErrWaitlistNotPromoted is returned with the updated class when its waitlists could not be promoted
+// into a raised capacity, the class change is stored and the spots left open can still be booked
+var ErrWaitlistNotPromoted = errors.New("class is updated but its waitlist could not be promoted")
+
+// ClassProcessor implements the business logic of classes on top of the class, booking and resource repositories
+type ClassProcessor struct {
+	classes   storage.ClassRepository
+	bookings  storage.BookingRepository
+	resources storage.ResourceRepository
+
+	//mu guards the class registry and the bookings made against it, it is shared with the
+	//booking processor. Writes hold it exclusively so the overlap, capacity and booking checks
+	//and the following write are a single step, lookups share it.
+	mu sync.RWMutex
+
+	//promote promotes the first member on the waitlist of a class session who can pay, it is
+	//set by the booking processor sharing mu. Caller must hold mu.
+	promote func(className string, session time.Time) (promoted bool, err error)
+}
+
+// NewClassProcessor creates a class processor which stores classes in the repository and
+// checks changes against the bookings of the class, instructors and rooms are kept in resources
+func NewClassProcessor(classes storage.ClassRepository, bookings storage.BookingRepository, resources storage.ResourceRepository) *ClassProcessor {
+	return &ClassProcessor{classes: classes, bookings: bookings, resources: resources}
+}
 
-// CreateClass adds a new class to the list
-// input name, startDate, endDate, capacity
+// CreateClass adds a new class to the list, a class without schedule runs a single
+// all day session on every date between startDate and endDate. A class without
+// cancellation policy can be cancelled without penalty. The instructor and room are
+// optional, 0 leaves them unset.
+// input name, startDate, endDate, capacity, schedule, cancellation policy, instructor id, room id
 // output classobject, error
-func CreateClass(name string, startDate, endDate time.Time, capacity int) (structs.Class, error) {
+func (p *ClassProcessor) CreateClass(name string, startDate, endDate time.Time, capacity int, schedule *structs.Schedule, policy *structs.CancellationPolicy, instructorID, roomID int) (structs.Class, error) {
 
-	// Before adding the new class, looping through the existing classes and
-	// check if any dates are overlapping. If any conflict is found, an error message is returned,
-	// and the class is not created.
+	defer p.mu.Unlock()
+	p.mu.Lock()
 
-	for _, existingClass := range classes {
-		if existingClass.ClassName == name {
-			if (startDate.Before(existingClass.EndDate) && endDate.After(existingClass.StartDate)) ||
-				startDate.Equal(existingClass.StartDate) || endDate.Equal(existingClass.EndDate) {
-				return structs.Class{}, errors.New("class date conflicts with existing class schedule")
-			}
-		}
+	if err := validateSchedule(schedule); err != nil {
+		return structs.Class{}, err
 	}
 
 	newClass := structs.Class{
-		ID:        int(classID),
 		ClassName: name,
 		StartDate: startDate,
 		EndDate:   endDate,
 		Capacity:  capacity,
+		Schedule:  schedule,
+
+		CancellationPolicy: policy,
+
+		InstructorID: instructorID,
+		RoomID:       roomID,
+	}
+
+	if err := p.checkResources(newClass); err != nil {
+		return structs.Class{}, err
+	}
+
+	if err := p.checkConflicts(newClass); err != nil {
+		return structs.Class{}, err
+	}
+
+	//the repository assigns the unique ID of the class, storage backends with
+	//schedule constraints reject the overlap as well
+	newClass, err := p.classes.CreateClass(newClass)
+	if errors.Is(err, storage.ErrConflict) {
+		return structs.Class{}, ErrClassConflict
+	}
+	return newClass, err
+}
+
+// ListClasses returns the classes matching the filters, an empty name matches every class
+// and zero from/to dates leave the range open. A class matches when its schedule
+// overlaps the from-to range.
+func (p *ClassProcessor) ListClasses(name string, from, to time.Time) ([]structs.Class, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	classes, err := p.classes.ListClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []structs.Class{}
+	for _, class := range classes {
+		if name != "" && class.ClassName != name {
+			continue
+		}
+		if !from.IsZero() && class.EndDate.Before(from) {
+			continue
+		}
+		if !to.IsZero() && class.StartDate.After(to) {
+			continue
+		}
+		result = append(result, class)
+	}
+	return result, nil
+}
+
+// GetClass returns the class with the id
+func (p *ClassProcessor) GetClass(id int) (structs.Class, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	return p.getClass(id)
+}
+
+// getClass returns the class with the id, caller must hold p.mu
+func (p *ClassProcessor) getClass(id int) (structs.Class, error) {
+	class, err := p.classes.GetClass(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Class{}, ErrClassNotFound
+	}
+	return class, err
+}
+
+// UpdateClass changes the capacity and dates of the class, nil values are left unchanged.
+// The new schedule is checked for overlaps like CreateClass and the change is refused
+// when existing bookings would fall outside the dates or above the capacity. Raising the
+// capacity promotes waitlisted members into the new spots of each session, each promotion is
+// stored on its own. When one fails the updated class is returned with ErrWaitlistNotPromoted.
+// input id, startDate, endDate, capacity
+// output updated class, error
+func (p *ClassProcessor) UpdateClass(id int, startDate, endDate *time.Time, capacity *int) (structs.Class, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	class, err := p.getClass(id)
+	if err != nil {
+		return structs.Class{}, err
+	}
+
+	if startDate != nil {
+		class.StartDate = *startDate
+	}
+	if endDate != nil {
+		class.EndDate = *endDate
+	}
+	raised := capacity != nil && *capacity > class.Capacity
+	if capacity != nil {
+		class.Capacity = *capacity
+	}
+
+	if class.StartDate.After(class.EndDate) {
+		return structs.Class{}, ErrInvalidClassDates
+	}
+
+	if err := p.checkConflicts(class); err != nil {
+		return structs.Class{}, err
+	}
+
+	bookings, err := p.classBookings(class)
+	if err != nil {
+		return structs.Class{}, err
+	}
+
+	//count the bookings of this class per session, bookings outside the schedule would be stranded
+	bookingsPerSession := make(map[int64]int)
+	for _, booking := range bookings {
+		scheduled, err := hasSession(class, booking.ClassDate)
+		if err != nil {
+			return structs.Class{}, err
+		}
+		if !scheduled {
+			return structs.Class{}, ErrBookingsOutsideDates
+		}
+		bookingsPerSession[booking.ClassDate.Unix()]++
+	}
+	for _, count := range bookingsPerSession {
+		if count > class.Capacity {
+			return structs.Class{}, ErrCapacityBelowBookings
+		}
+	}
+
+	err = p.classes.UpdateClass(class)
+	if errors.Is(err, storage.ErrConflict) {
+		return structs.Class{}, ErrClassConflict
+	}
+	if err != nil {
+		return structs.Class{}, err
+	}
+
+	if raised {
+		if err := p.promoteWaitlists(class, bookingsPerSession); err != nil {
+			return class, fmt.Errorf("%w: %v", ErrWaitlistNotPromoted, err)
+		}
+	}
+	return class, nil
+}
+
+// promoteWaitlists promotes waitlisted members of every session of the class in FIFO order until
+// the session is full, booked counts the bookings per session start. Promoted members pay with
+// a credit like on a cancellation. Caller must hold p.mu.
+func (p *ClassProcessor) promoteWaitlists(class structs.Class, booked map[int64]int) error {
+	if p.promote == nil {
+		return nil
+	}
+	sessions, err := sessionsBetween(class, class.StartDate, class.EndDate)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		for count := booked[session.Unix()]; count < class.Capacity; count++ {
+			promoted, err := p.promote(class.ClassName, session)
+			if err != nil {
+				return err
+			}
+			if !promoted {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteClass removes the class, classes with bookings cannot be deleted
+// input id
+// output error
+func (p *ClassProcessor) DeleteClass(id int) error {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	class, err := p.getClass(id)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := p.classBookings(class)
+	if err != nil {
+		return err
+	}
+	if len(bookings) > 0 {
+		return ErrClassHasBookings
+	}
+
+	err = p.classes.DeleteClass(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrClassNotFound
+	}
+	return err
+}
+
+// checkConflicts looks for an other class sharing the name, instructor or room of the class which
+// has a session running at the same time as a session of the class, the class never conflicts
+// with itself. The error names the clashing class, what it shares and when. Caller must hold
+// p.mu, the stores only guard classes without schedule by name so this is the only check of
+// sessions, instructors and rooms.
+func (p *ClassProcessor) checkConflicts(class structs.Class) error {
+	existingClasses, err := p.classes.ListClasses()
+	if err != nil {
+		return err
+	}
+
+	// Before adding the new class, looping through the existing classes and
+	// check if any sessions are overlapping. If any conflict is found, an error message is returned,
+	// and the class is not created.
+
+	for _, existingClass := range existingClasses {
+		if existingClass.ID == class.ID {
+			continue
+		}
+		shared := sharedResources(class, existingClass)
+		if len(shared) == 0 {
+			continue
+		}
+		start, overlap, err := firstOverlap(existingClass, class)
+		if err != nil {
+			return err
+		}
+		if overlap {
+			return fmt.Errorf("%w: class %q (id %d) has the same %s at %s", ErrClassConflict,
+				existingClass.ClassName, existingClass.ID, strings.Join(shared, " and "), start.Format(DATEFORMAT+" "+TIMEFORMAT))
+		}
+	}
+	return nil
+}
+
+// sharedResources lists what the classes cannot share at the same time: their name, as
+// bookings refer to classes by name, their instructor and their room
+func sharedResources(a, b structs.Class) []string {
+	var shared []string
+	if a.ClassName == b.ClassName {
+		shared = append(shared, "name")
+	}
+	if a.InstructorID != 0 && a.InstructorID == b.InstructorID {
+		shared = append(shared, fmt.Sprintf("instructor %d", a.InstructorID))
+	}
+	if a.RoomID != 0 && a.RoomID == b.RoomID {
+		shared = append(shared, fmt.Sprintf("room %d", a.RoomID))
+	}
+	return shared
+}
+
+// classBookings returns the bookings which belong to the class. Bookings are stored by
+// class name, so bookings of a session of an other class with the same name belong to
+// that class instead.
+func (p *ClassProcessor) classBookings(class structs.Class) ([]structs.Booking, error) {
+	classes, err := p.classes.ListClasses()
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := p.bookings.ListBookingsByClass(class.ClassName)
+	if err != nil {
+		return nil, err
 	}
 
-	//atomic incrementation helps race condition while working with concurrent requests
-	atomic.AddInt64(&classID, 1)
+	var result []structs.Booking
+	for _, booking := range bookings {
+		owned := true
+		for _, other := range classes {
+			if other.ID == class.ID || other.ClassName != class.ClassName {
+				continue
+			}
+			scheduled, err := hasSession(other, booking.ClassDate)
+			if err != nil {
+				return nil, err
+			}
+			if scheduled {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
 
-	classes = append(classes, newClass)
-	return newClass, nil
+// findScheduled looks up the class with the name which has a session starting at the
+// session time, caller must hold p.mu
+func (p *ClassProcessor) findScheduled(class_name string, session time.Time) (structs.Class, error) {
+	classes, err := p.classes.ListClasses()
+	if err != nil {
+		return structs.Class{}, err
+	}
+
+	//looping through classes to find any classname matches with a session at the time
+	for _, existingClass := range classes {
+		if existingClass.ClassName != class_name {
+			continue
+		}
+		scheduled, err := hasSession(existingClass, session)
+		if err != nil {
+			return structs.Class{}, err
+		}
+		if scheduled {
+			return existingClass, nil
+		}
+	}
+	return structs.Class{}, ErrClassNotScheduled
 }
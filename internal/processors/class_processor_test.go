@@ -1,8 +1,12 @@
 package processors
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
 )
 
 func TestCreateClass(t *testing.T) {
@@ -11,7 +15,9 @@ func TestCreateClass(t *testing.T) {
 	endDate, _ := time.Parse(DATEFORMAT, "2025-02-28")
 	capacity := 100
 	// Create class
-	class, err := CreateClass(className, startDate, endDate, capacity)
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	class, err := classProcessor.CreateClass(className, startDate, endDate, capacity, nil, nil, 0, 0)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -28,3 +34,174 @@ func TestCreateClass(t *testing.T) {
 	}
 
 }
+
+func TestUpdateClass(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	startDate, _ := time.Parse(DATEFORMAT, "2025-02-20")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-02-28")
+
+	class, _ := classProcessor.CreateClass("yoga", startDate, endDate, 2, nil, nil, 0, 0)
+	other, _ := classProcessor.CreateClass("yoga", endDate.AddDate(0, 0, 5), endDate.AddDate(0, 0, 10), 2, nil, nil, 0, 0)
+
+	// Extending into the next yoga class is an overlap
+	extended := endDate.AddDate(0, 0, 6)
+	if _, err := classProcessor.UpdateClass(class.ID, nil, &extended, nil); !errors.Is(err, ErrClassConflict) {
+		t.Fatalf("expected %v, got %v", ErrClassConflict, err)
+	}
+
+	// A class does not conflict with its own schedule
+	extended = endDate.AddDate(0, 0, 2)
+	if _, err := classProcessor.UpdateClass(class.ID, nil, &extended, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bookingProcessor.BookClass("yoga", "Sai Kumar", startDate)
+	bookingProcessor.BookClass("yoga", "John", startDate)
+	bookingProcessor.BookClass("yoga", "Jane", other.StartDate)
+
+	capacity := 1
+	if _, err := classProcessor.UpdateClass(class.ID, nil, nil, &capacity); err != ErrCapacityBelowBookings {
+		t.Fatalf("expected %v, got %v", ErrCapacityBelowBookings, err)
+	}
+
+	shifted := startDate.AddDate(0, 0, 1)
+	if _, err := classProcessor.UpdateClass(class.ID, &shifted, nil, nil); err != ErrBookingsOutsideDates {
+		t.Fatalf("expected %v, got %v", ErrBookingsOutsideDates, err)
+	}
+
+	// The booking of Jane belongs to the other yoga class and does not block this one
+	capacity = 2
+	updated, err := classProcessor.UpdateClass(class.ID, nil, nil, &capacity)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !updated.EndDate.Equal(extended) {
+		t.Fatalf("expected end date %v, got %v", extended, updated.EndDate)
+	}
+
+	if _, err := classProcessor.UpdateClass(99, nil, nil, &capacity); err != ErrClassNotFound {
+		t.Fatalf("expected %v, got %v", ErrClassNotFound, err)
+	}
+}
+
+func TestUpdateClass_PromotesWaitlist(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+	startDate, _ := time.Parse(DATEFORMAT, "2025-02-20")
+	endDate := startDate.AddDate(0, 0, 1)
+
+	class, _ := classProcessor.CreateClass("yoga", startDate, endDate, 1, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("John", "john@example.com", "")
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 4})
+
+	bookingProcessor.BookClass("yoga", "Sai Kumar", startDate)
+	john, _ := bookingProcessor.JoinWaitlist("yoga", "John", startDate)
+	jane, _ := bookingProcessor.JoinWaitlist("yoga", "Jane", startDate)
+	bookingProcessor.BookClass("yoga", "Ann", endDate)
+	bob, _ := bookingProcessor.JoinWaitlist("yoga", "Bob", endDate)
+	if balance, _ := memberProcessor.GetBalance(member.ID, startDate); *balance.Remaining != 4 {
+		t.Fatalf("expected 4 credits left, got %d", *balance.Remaining)
+	}
+
+	// Each session fills its new spot from the front of its waitlist
+	capacity := 2
+	if _, err := classProcessor.UpdateClass(class.ID, nil, nil, &capacity); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, id := range []int{john.ID, bob.ID} {
+		if promoted, _ := bookingProcessor.GetBooking(id); promoted.Status != structs.BookingStatusBooked {
+			t.Fatalf("expected booking %d to be promoted, got %v", id, promoted)
+		}
+	}
+	if waiting, _ := bookingProcessor.GetBooking(jane.ID); waiting.Status != structs.BookingStatusWaitlisted || waiting.WaitlistPosition != 1 {
+		t.Fatalf("expected Jane to wait first in line, got %v", waiting)
+	}
+
+	// The promoted member pays with a credit when promoted, not when joining the waitlist
+	if promoted, _ := bookingProcessor.GetBooking(john.ID); !promoted.CreditUsed {
+		t.Fatalf("expected the promoted booking to use a credit, got %v", promoted)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, startDate); *balance.Remaining != 3 {
+		t.Fatalf("expected 3 credits left, got %d", *balance.Remaining)
+	}
+}
+
+func TestUpdateClass_PromotionFails(t *testing.T) {
+	store := &failingStore{MemoryStore: storage.NewMemoryStore()}
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-20")
+
+	class, _ := classProcessor.CreateClass("yoga", classDate, classDate, 1, nil, nil, 0, 0)
+	bookingProcessor.BookClass("yoga", "Sai Kumar", classDate)
+	waiting, _ := bookingProcessor.JoinWaitlist("yoga", "John", classDate)
+
+	// The raised capacity is kept although the waitlist could not be promoted
+	store.fail = true
+	capacity := 2
+	updated, err := classProcessor.UpdateClass(class.ID, nil, nil, &capacity)
+	if !errors.Is(err, ErrWaitlistNotPromoted) || updated.Capacity != 2 {
+		t.Fatalf("expected the updated class with %v, got %v, %v", ErrWaitlistNotPromoted, updated, err)
+	}
+	if found, _ := classProcessor.GetClass(class.ID); found.Capacity != 2 {
+		t.Fatalf("expected capacity 2 to be stored, got %v", found)
+	}
+	if found, _ := bookingProcessor.GetBooking(waiting.ID); found.Status != structs.BookingStatusWaitlisted {
+		t.Fatalf("expected John to keep waiting, got %v", found)
+	}
+}
+
+func TestDeleteClass(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	bookingProcessor := NewBookingProcessor(classProcessor, NewMemberProcessor(store), store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-20")
+
+	class, _ := classProcessor.CreateClass("yoga", classDate, classDate, 2, nil, nil, 0, 0)
+	bookingProcessor.BookClass("yoga", "Sai Kumar", classDate)
+
+	if err := classProcessor.DeleteClass(class.ID); err != ErrClassHasBookings {
+		t.Fatalf("expected %v, got %v", ErrClassHasBookings, err)
+	}
+
+	bookingProcessor.CancelBooking("yoga", "Sai Kumar", classDate)
+	if err := classProcessor.DeleteClass(class.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := classProcessor.GetClass(class.ID); err != ErrClassNotFound {
+		t.Fatalf("expected %v, got %v", ErrClassNotFound, err)
+	}
+}
+
+func TestCreateClass_Schedule(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-31")
+
+	mornings := &structs.Schedule{Weekdays: []string{"mon", "wed", "fri"}, StartTimes: []string{"07:00"}, Duration: 60}
+	if _, err := classProcessor.CreateClass("yoga", startDate, endDate, 10, mornings, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Sessions on other days or times do not conflict even though the dates overlap
+	evenings := &structs.Schedule{Weekdays: []string{"mon", "wed", "fri"}, StartTimes: []string{"18:30"}, Duration: 60}
+	if _, err := classProcessor.CreateClass("yoga", startDate, endDate, 10, evenings, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	overlapping := &structs.Schedule{RRule: "FREQ=WEEKLY;BYDAY=FR", StartTimes: []string{"07:30"}, Duration: 45}
+	if _, err := classProcessor.CreateClass("yoga", startDate, endDate, 10, overlapping, nil, 0, 0); !errors.Is(err, ErrClassConflict) {
+		t.Fatalf("expected %v, got %v", ErrClassConflict, err)
+	}
+
+	invalid := &structs.Schedule{StartTimes: []string{"7pm"}, Duration: 45}
+	if _, err := classProcessor.CreateClass("pilates", startDate, endDate, 10, invalid, nil, 0, 0); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected %v, got %v", ErrInvalidSchedule, err)
+	}
+}
@@ -0,0 +1,34 @@
+package processors
+
+// Kind is the category of a domain error, the API responds with the HTTP status of the kind
+type Kind int
+
+const (
+	// KindInternal errors are failures of the service itself, e.g. of the store
+	KindInternal Kind = iota
+	// KindInvalid errors are requests which can never succeed as they are
+	KindInvalid
+	// KindUnauthorized errors are requests without valid credentials
+	KindUnauthorized
+	// KindForbidden errors are requests the credentials do not allow
+	KindForbidden
+	// KindNotFound errors are requests for something which does not exist
+	KindNotFound
+	// KindConflict errors are requests which clash with the current state, e.g. a full class
+	KindConflict
+	// KindUnavailable errors are requests the service cannot serve for now, e.g. while its store is down
+	KindUnavailable
+)
+
+// Error is a domain error with a stable machine readable code, clients match on the code
+// as the message may change. Errors are compared with errors.Is, wrapping them with %w adds
+// details without changing the code.
+type Error struct {
+	Code    string
+	Kind    Kind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,34 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Codes(t *testing.T) {
+	catalog := []*Error{
+		ErrClassConflict, ErrClassNotFound, ErrInvalidClassDates, ErrBookingsOutsideDates, ErrCapacityBelowBookings,
+		ErrClassHasBookings, ErrClassInPast, ErrClassFull, ErrBookingNotFound, ErrClassNotScheduled, ErrAlreadyBooked,
+		ErrBookingInPast, ErrMemberNotFound, ErrMemberExists, ErrInvalidSchedule, ErrStudioNotFound,
+		ErrInvalidTimezone, ErrInvalidCursor, ErrTooManyStrikes, ErrNotBooked, ErrAttendanceRecorded, ErrSessionNotStarted,
+		ErrCheckInClosed, ErrNoCredit, ErrNoMembership, ErrInvalidMembership,
+		ErrInstructorNotFound, ErrRoomNotFound, ErrBookingCancelled,
+	}
+
+	// Codes are what clients match on, two errors never share one
+	codes := make(map[string]bool)
+	for _, err := range catalog {
+		if err.Code == "" || err.Kind == KindInternal || codes[err.Code] {
+			t.Errorf("expected a unique code and a kind for %q, got %q, %d", err.Message, err.Code, err.Kind)
+		}
+		codes[err.Code] = true
+	}
+
+	// Wrapped errors keep their code
+	var domainErr *Error
+	wrapped := fmt.Errorf("%w: duration must be between 1 and 1440 minutes", ErrInvalidSchedule)
+	if !errors.As(wrapped, &domainErr) || domainErr.Code != "invalid_schedule" || !errors.Is(wrapped, ErrInvalidSchedule) {
+		t.Errorf("expected the invalid_schedule code, got %v", domainErr)
+	}
+}
@@ -0,0 +1,135 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+var (
+	ErrMemberNotFound = &Error{Code: "member_not_found", Kind: KindNotFound, Message: "member not found"}
+	ErrMemberExists   = &Error{Code: "member_exists", Kind: KindConflict, Message: "a member with the same name is already registered"}
+)
+
+// MemberProcessor implements the registry of studio members on top of the member repository
+type MemberProcessor struct {
+	members storage.MemberRepository
+
+	//mu makes the name check and the write of a member a single step. The booking processor
+	//holds it while it computes and stores the credits and strikes a booking changes, after
+	//the lock of its class processor.
+	mu sync.Mutex
+}
+
+// NewMemberProcessor creates a member processor which stores members in the repository
+func NewMemberProcessor(members storage.MemberRepository) *MemberProcessor {
+	return &MemberProcessor{members: members}
+}
+
+// CreateMember registers a new member, names are unique after normalization
+// since bookings refer to members by name
+// input name, email, phone
+// output member, error
+func (p *MemberProcessor) CreateMember(name, email, phone string) (structs.Member, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	_, err := p.members.FindMemberByName(name)
+	if err == nil {
+		return structs.Member{}, ErrMemberExists
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return structs.Member{}, err
+	}
+
+	member, err := p.members.CreateMember(structs.Member{Name: strings.TrimSpace(name), Email: email, Phone: phone})
+	if errors.Is(err, storage.ErrConflict) {
+		return structs.Member{}, ErrMemberExists
+	}
+	return member, err
+}
+
+// GetMember returns the member with the id
+func (p *MemberProcessor) GetMember(id int) (structs.Member, error) {
+	member, err := p.members.GetMember(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Member{}, ErrMemberNotFound
+	}
+	return member, err
+}
+
+// UpdateMember changes the contact details of the member, nil values are left unchanged
+// input id, email, phone
+// output updated member, error
+func (p *MemberProcessor) UpdateMember(id int, email, phone *string) (structs.Member, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	member, err := p.GetMember(id)
+	if err != nil {
+		return structs.Member{}, err
+	}
+
+	if email != nil {
+		member.Email = *email
+	}
+	if phone != nil {
+		member.Phone = *phone
+	}
+
+	if err := p.members.UpdateMember(member); err != nil {
+		return structs.Member{}, err
+	}
+	return member, nil
+}
+
+// ResetStrikes clears the late cancellations and no-shows of the member, so a blocked
+// member can book again
+// input id
+// output updated member, error
+func (p *MemberProcessor) ResetStrikes(id int) (structs.Member, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	member, err := p.GetMember(id)
+	if err != nil {
+		return structs.Member{}, err
+	}
+
+	member.Strikes = 0
+	if err := p.members.UpdateMember(member); err != nil {
+		return structs.Member{}, err
+	}
+	return member, nil
+}
+
+// withStrike returns the member with the name given one more strike for a late cancellation or
+// no-show, registered is false for unregistered names which have no strikes. Caller must hold p.mu
+// and store the member.
+func (p *MemberProcessor) withStrike(name string) (member structs.Member, registered bool, err error) {
+	member, registered, err = p.findByName(name)
+	if err != nil || !registered {
+		return structs.Member{}, false, err
+	}
+
+	member.Strikes++
+	return member, true, nil
+}
+
+// findByName returns the registered member with the name, ok is false for unregistered names
+func (p *MemberProcessor) findByName(name string) (structs.Member, bool, error) {
+	member, err := p.members.FindMemberByName(name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Member{}, false, nil
+	}
+	if err != nil {
+		return structs.Member{}, false, err
+	}
+	return member, true, nil
+}
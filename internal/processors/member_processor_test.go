@@ -0,0 +1,66 @@
+package processors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+)
+
+func TestCreateMember(t *testing.T) {
+	memberProcessor := NewMemberProcessor(storage.NewMemoryStore())
+
+	member, err := memberProcessor.CreateMember(" Sai Kumar ", "sai@example.com", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if member.ID == 0 || member.Name != "Sai Kumar" {
+		t.Fatalf("expected member Sai Kumar with an id, got %v", member)
+	}
+
+	if _, err := memberProcessor.CreateMember("SAI KUMAR", "other@example.com", ""); err != ErrMemberExists {
+		t.Fatalf("expected %v, got %v", ErrMemberExists, err)
+	}
+}
+
+func TestUpdateMember(t *testing.T) {
+	memberProcessor := NewMemberProcessor(storage.NewMemoryStore())
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "+14155552671")
+
+	email := "kumar@example.com"
+	updated, err := memberProcessor.UpdateMember(member.ID, &email, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.Email != email || updated.Phone != member.Phone {
+		t.Fatalf("expected only the email to change, got %v", updated)
+	}
+
+	if _, err := memberProcessor.UpdateMember(99, &email, nil); err != ErrMemberNotFound {
+		t.Fatalf("expected %v, got %v", ErrMemberNotFound, err)
+	}
+}
+
+func TestBookClass_RegisteredMember(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor, memberProcessor := NewClassProcessor(store, store, store), NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-15")
+	classProcessor.CreateClass("yoga", classDate, classDate, 10, nil, nil, 0, 0)
+
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+
+	// Bookings by name are linked to the registered member
+	booking, err := bookingProcessor.BookClass("yoga", "sai kumar", classDate)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if booking.MemberID != member.ID || booking.MemberName != "Sai Kumar" {
+		t.Fatalf("expected booking of member %d, got %v", member.ID, booking)
+	}
+
+	guest, _ := bookingProcessor.BookClass("yoga", "Guest", classDate)
+	if guest.MemberID != 0 {
+		t.Fatalf("expected no member id for unregistered names, got %d", guest.MemberID)
+	}
+}
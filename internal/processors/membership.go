@@ -0,0 +1,161 @@
+package processors
+
+import (
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+const MONTHFORMAT = "2006-01"
+
+var (
+	// ErrNoCredit is returned when the membership of a member cannot pay for a session
+	ErrNoCredit          = &Error{Code: "no_credit", Kind: KindForbidden, Message: "member has no class credit left for the session"}
+	ErrNoMembership      = &Error{Code: "membership_not_found", Kind: KindNotFound, Message: "member has no membership"}
+	ErrInvalidMembership = &Error{Code: "invalid_membership", Kind: KindInvalid, Message: "monthly plans and packs need credits and packs need an expiry date"}
+)
+
+// AssignMembership gives the member a plan, replacing the current one along with the credits
+// used of it. Bookings paid by the replaced plan are not refunded to the new one.
+// input id, membership
+// output updated member, error
+func (p *MemberProcessor) AssignMembership(id int, membership structs.Membership) (structs.Member, error) {
+	if membership.Plan != structs.PlanUnlimited && membership.Credits <= 0 {
+		return structs.Member{}, ErrInvalidMembership
+	}
+	if membership.Plan == structs.PlanPack && membership.ExpiresOn == nil {
+		return structs.Member{}, ErrInvalidMembership
+	}
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	member, err := p.GetMember(id)
+	if err != nil {
+		return structs.Member{}, err
+	}
+
+	membership.Used = nil
+	membership.Assignment = 1
+	if member.Membership != nil {
+		membership.Assignment = member.Membership.Assignment + 1
+	}
+	member.Membership = &membership
+	if err := p.members.UpdateMember(member); err != nil {
+		return structs.Member{}, err
+	}
+	return member, nil
+}
+
+// GetBalance returns what the member can still book with their membership, the credits
+// of a monthly plan are those of the month of the date
+// input id, date
+// output balance, error
+func (p *MemberProcessor) GetBalance(id int, date time.Time) (structs.Balance, error) {
+	member, err := p.GetMember(id)
+	if err != nil {
+		return structs.Balance{}, err
+	}
+
+	membership := member.Membership
+	if membership == nil {
+		return structs.Balance{}, ErrNoMembership
+	}
+
+	balance := structs.Balance{MemberID: member.ID, Plan: membership.Plan, ExpiresOn: membership.ExpiresOn}
+	switch membership.Plan {
+	case structs.PlanMonthly:
+		balance.Period = date.Format(MONTHFORMAT)
+		remaining := max(membership.Credits-membership.Used[balance.Period], 0)
+		balance.Remaining = &remaining
+	case structs.PlanPack:
+		remaining := membership.Credits
+		balance.Remaining = &remaining
+	}
+	return balance, nil
+}
+
+// withCreditUsed returns the member with a credit of their membership used for a session, used
+// is false when nothing is charged. Members without a plan book freely unless a membership is
+// required, unregistered names never have one. Caller must hold p.mu and store the member.
+func (p *MemberProcessor) withCreditUsed(name string, session time.Time, required bool) (member structs.Member, used bool, err error) {
+	member, registered, err := p.findByName(name)
+	if err != nil {
+		return structs.Member{}, false, err
+	}
+	if !registered || member.Membership == nil {
+		if required {
+			return structs.Member{}, false, ErrNoCredit
+		}
+		return structs.Member{}, false, nil
+	}
+
+	membership := cloneMembership(member.Membership)
+	if membership.ExpiresOn != nil && session.Format(DATEFORMAT) > membership.ExpiresOn.Format(DATEFORMAT) {
+		return structs.Member{}, false, ErrNoCredit
+	}
+
+	switch membership.Plan {
+	case structs.PlanUnlimited:
+		return structs.Member{}, false, nil
+	case structs.PlanMonthly:
+		month := session.Format(MONTHFORMAT)
+		if membership.Used[month] >= membership.Credits {
+			return structs.Member{}, false, ErrNoCredit
+		}
+		if membership.Used == nil {
+			membership.Used = make(map[string]int)
+		}
+		membership.Used[month]++
+	default:
+		if membership.Credits <= 0 {
+			return structs.Member{}, false, ErrNoCredit
+		}
+		membership.Credits--
+	}
+
+	member.Membership = membership
+	return member, true, nil
+}
+
+// withCreditRefunded returns the member with the credit a booking used for the session given
+// back to the plan of the assignment which paid for it, refunded is false when a plan assigned
+// since then is left as it is. Caller must hold p.mu and store the member.
+func (p *MemberProcessor) withCreditRefunded(name string, session time.Time, assignment int) (member structs.Member, refunded bool, err error) {
+	member, registered, err := p.findByName(name)
+	if err != nil || !registered || member.Membership == nil || member.Membership.Assignment != assignment {
+		return structs.Member{}, false, err
+	}
+
+	membership := cloneMembership(member.Membership)
+	switch membership.Plan {
+	case structs.PlanUnlimited:
+		return structs.Member{}, false, nil
+	case structs.PlanMonthly:
+		month := session.Format(MONTHFORMAT)
+		if membership.Used[month] == 0 {
+			return structs.Member{}, false, nil
+		}
+		membership.Used[month]--
+		if membership.Used[month] == 0 {
+			delete(membership.Used, month)
+		}
+	default:
+		membership.Credits++
+	}
+
+	member.Membership = membership
+	return member, true, nil
+}
+
+// cloneMembership copies the membership so changing it leaves the stored member untouched
+func cloneMembership(membership *structs.Membership) *structs.Membership {
+	clone := *membership
+	if membership.Used != nil {
+		clone.Used = make(map[string]int, len(membership.Used))
+		for month, used := range membership.Used {
+			clone.Used[month] = used
+		}
+	}
+	return &clone
+}
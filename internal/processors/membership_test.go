@@ -0,0 +1,263 @@
+package processors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestAssignMembership(t *testing.T) {
+	memberProcessor := NewMemberProcessor(storage.NewMemoryStore())
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	march, _ := time.Parse(DATEFORMAT, "2025-03-10")
+
+	if _, err := memberProcessor.GetBalance(member.ID, march); err != ErrNoMembership {
+		t.Fatalf("expected %v, got %v", ErrNoMembership, err)
+	}
+
+	// Monthly plans and packs need credits, packs also an expiry date
+	invalid := []structs.Membership{
+		{Plan: structs.PlanMonthly},
+		{Plan: structs.PlanPack, Credits: 5},
+	}
+	for _, membership := range invalid {
+		if _, err := memberProcessor.AssignMembership(member.ID, membership); err != ErrInvalidMembership {
+			t.Errorf("expected %v for %v, got %v", ErrInvalidMembership, membership, err)
+		}
+	}
+	if _, err := memberProcessor.AssignMembership(99, structs.Membership{Plan: structs.PlanUnlimited}); err != ErrMemberNotFound {
+		t.Fatalf("expected %v, got %v", ErrMemberNotFound, err)
+	}
+
+	updated, err := memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 8, Used: map[string]int{"2025-03": 8}, Assignment: 5})
+	if err != nil || updated.Membership == nil || updated.Membership.Used != nil || updated.Membership.Assignment != 1 {
+		t.Fatalf("expected a first monthly plan without used credits, got %v, %v", updated.Membership, err)
+	}
+	balance, err := memberProcessor.GetBalance(member.ID, march)
+	if err != nil || balance.Period != "2025-03" || balance.Remaining == nil || *balance.Remaining != 8 {
+		t.Fatalf("expected 8 credits left in 2025-03, got %v, %v", balance, err)
+	}
+
+	// Unlimited plans have no credits to count
+	updated, _ = memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanUnlimited})
+	if balance, _ := memberProcessor.GetBalance(member.ID, march); balance.Plan != structs.PlanUnlimited || balance.Remaining != nil {
+		t.Fatalf("expected an unlimited balance, got %v", balance)
+	}
+	if updated.Membership.Assignment != 2 {
+		t.Fatalf("expected the second assignment, got %d", updated.Membership.Assignment)
+	}
+}
+
+func TestBookClass_Credits(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-30")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-04-02")
+	classProcessor.CreateClass("yoga", startDate, endDate, 1, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 1})
+
+	booking, err := bookingProcessor.BookClass("yoga", "Sai Kumar", startDate)
+	if err != nil || !booking.CreditUsed {
+		t.Fatalf("expected a booking using a credit, got %v, %v", booking, err)
+	}
+	if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", startDate.AddDate(0, 0, 1)); err != ErrNoCredit {
+		t.Fatalf("expected %v, got %v", ErrNoCredit, err)
+	}
+
+	// Credits of a monthly plan are counted by the month of the session
+	april := endDate.AddDate(0, 0, -1)
+	if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", april); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Cancelling in time refunds the credit
+	bookingProcessor.CancelBookingByID(booking.ID)
+	if balance, _ := memberProcessor.GetBalance(member.ID, startDate); *balance.Remaining != 1 {
+		t.Fatalf("expected the credit to be refunded, got %d", *balance.Remaining)
+	}
+
+	// Joining the waitlist uses no credit yet
+	bookingProcessor.BookClass("yoga", "John", endDate)
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 1})
+	mary, _ := memberProcessor.CreateMember("Mary", "mary@example.com", "")
+	memberProcessor.AssignMembership(mary.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 1})
+	first, _ := bookingProcessor.JoinWaitlist("yoga", "Mary", endDate)
+	waiting, err := bookingProcessor.JoinWaitlist("yoga", "Sai Kumar", endDate)
+	if err != nil || waiting.Status != structs.BookingStatusWaitlisted || waiting.CreditUsed {
+		t.Fatalf("expected a waitlist entry without a credit, got %v, %v", waiting, err)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, endDate); *balance.Remaining != 1 {
+		t.Fatalf("expected the credit to be kept, got %d", *balance.Remaining)
+	}
+
+	// The credit is used on promotion, a member who cannot pay then keeps waiting
+	memberProcessor.AssignMembership(mary.ID, structs.Membership{Plan: structs.PlanPack, Credits: 1, ExpiresOn: &startDate})
+	bookingProcessor.CancelBooking("yoga", "John", endDate)
+	if promoted, _ := bookingProcessor.GetBooking(waiting.ID); promoted.Status != structs.BookingStatusBooked || !promoted.CreditUsed {
+		t.Fatalf("expected Sai Kumar to be promoted using a credit, got %v", promoted)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, endDate); *balance.Remaining != 0 {
+		t.Fatalf("expected no credit left, got %d", *balance.Remaining)
+	}
+	if found, _ := bookingProcessor.GetBooking(first.ID); found.Status != structs.BookingStatusWaitlisted || found.WaitlistPosition != 1 {
+		t.Fatalf("expected Mary to keep waiting, got %v", found)
+	}
+
+	// Members who cannot pay are refused the waitlist
+	bookingProcessor.CancelBooking("yoga", "Mary", endDate)
+	if _, err := bookingProcessor.JoinWaitlist("yoga", "Mary", endDate); err != ErrNoCredit {
+		t.Fatalf("expected %v, got %v", ErrNoCredit, err)
+	}
+}
+
+// failingStore fails every booking change while fail is set
+type failingStore struct {
+	*storage.MemoryStore
+	fail bool
+}
+
+func (s *failingStore) ApplyChange(change storage.BookingChange) (structs.Booking, error) {
+	if s.fail {
+		return structs.Booking{}, errors.New("disk full")
+	}
+	return s.MemoryStore.ApplyChange(change)
+}
+
+func TestBookClass_CreditsStoredWithBooking(t *testing.T) {
+	store := &failingStore{MemoryStore: storage.NewMemoryStore()}
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	classProcessor.CreateClass("yoga", classDate, classDate.AddDate(0, 0, 1), 5, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanMonthly, Credits: 2})
+	booking, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate)
+
+	// A booking or cancellation which cannot be stored leaves the credits as they were
+	store.fail = true
+	if _, err := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate.AddDate(0, 0, 1)); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := bookingProcessor.CancelBookingByID(booking.ID); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, classDate); *balance.Remaining != 1 {
+		t.Fatalf("expected 1 credit left, got %d", *balance.Remaining)
+	}
+	if found, _ := bookingProcessor.GetBooking(booking.ID); found.Status != structs.BookingStatusBooked {
+		t.Fatalf("expected the booking to stay booked, got %v", found)
+	}
+
+	store.fail = false
+	if _, err := bookingProcessor.CancelBookingByID(booking.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, classDate); *balance.Remaining != 2 {
+		t.Fatalf("expected the credit to be refunded, got %d", *balance.Remaining)
+	}
+}
+
+func TestBookClass_Pack(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-01")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-10")
+	expiresOn, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	classProcessor.CreateClass("spin", startDate, endDate, 5, nil, &structs.CancellationPolicy{LateCancelHours: 24}, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanPack, Credits: 2, ExpiresOn: &expiresOn})
+
+	// Sessions after the pack expires cannot use it
+	if _, err := bookingProcessor.BookClass("spin", "Sai Kumar", expiresOn.AddDate(0, 0, 1)); err != ErrNoCredit {
+		t.Fatalf("expected %v, got %v", ErrNoCredit, err)
+	}
+
+	booking, _ := bookingProcessor.BookClass("spin", "Sai Kumar", startDate)
+	bookingProcessor.BookClass("spin", "Sai Kumar", expiresOn)
+	if _, err := bookingProcessor.BookClass("spin", "Sai Kumar", startDate.AddDate(0, 0, 1)); err != ErrNoCredit {
+		t.Fatalf("expected %v, got %v", ErrNoCredit, err)
+	}
+
+	// A late cancellation keeps the credit
+	bookingProcessor.now = func() time.Time { return startDate.Add(-time.Hour) }
+	if cancelled, _ := bookingProcessor.CancelBookingByID(booking.ID); cancelled.Status != structs.BookingStatusLateCancelled {
+		t.Fatalf("expected status %s, got %v", structs.BookingStatusLateCancelled, cancelled)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, startDate); *balance.Remaining != 0 {
+		t.Fatalf("expected no credit left, got %d", *balance.Remaining)
+	}
+}
+
+func TestCancelBooking_RefundsPayingPlan(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	expiresOn := classDate.AddDate(0, 1, 0)
+	classProcessor.CreateClass("yoga", classDate, classDate.AddDate(0, 0, 1), 5, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanPack, Credits: 2, ExpiresOn: &expiresOn})
+
+	old, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate)
+	if old.CreditAssignment != 1 {
+		t.Fatalf("expected the booking to be paid by the first assignment, got %v", old)
+	}
+
+	// The credit of a replaced plan is not refunded to the new one
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanPack, Credits: 2, ExpiresOn: &expiresOn})
+	if _, err := bookingProcessor.CancelBookingByID(old.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if balance, _ := memberProcessor.GetBalance(member.ID, classDate); *balance.Remaining != 2 {
+		t.Fatalf("expected 2 credits left, got %d", *balance.Remaining)
+	}
+
+	// Bookings of the current plan are refunded to it
+	booking, _ := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate.AddDate(0, 0, 1))
+	bookingProcessor.CancelBookingByID(booking.ID)
+	if balance, _ := memberProcessor.GetBalance(member.ID, classDate); *balance.Remaining != 2 {
+		t.Fatalf("expected the credit to be refunded, got %d", *balance.Remaining)
+	}
+}
+
+func TestBookClass_RequireMembership(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+	memberProcessor := NewMemberProcessor(store)
+	bookingProcessor := NewBookingProcessor(classProcessor, memberProcessor, store)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	classProcessor.CreateClass("yoga", classDate, classDate, 5, nil, nil, 0, 0)
+	member, _ := memberProcessor.CreateMember("Sai Kumar", "sai@example.com", "")
+
+	// Without the rule members without a plan book freely
+	if booking, err := bookingProcessor.BookClass("yoga", "John", classDate); err != nil || booking.CreditUsed {
+		t.Fatalf("expected a booking without a credit, got %v, %v", booking, err)
+	}
+
+	bookingProcessor.SetRules(BookingRules{RequireMembership: true})
+	for _, name := range []string{"Sai Kumar", "Jane"} {
+		if _, err := bookingProcessor.BookClass("yoga", name, classDate); err != ErrNoCredit {
+			t.Errorf("expected %v for %s, got %v", ErrNoCredit, name, err)
+		}
+	}
+
+	memberProcessor.AssignMembership(member.ID, structs.Membership{Plan: structs.PlanUnlimited})
+	if booking, err := bookingProcessor.BookClass("yoga", "Sai Kumar", classDate); err != nil || booking.CreditUsed {
+		t.Fatalf("expected a booking without a credit, got %v, %v", booking, err)
+	}
+}
@@ -0,0 +1,112 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+var (
+	ErrInstructorNotFound = &Error{Code: "instructor_not_found", Kind: KindNotFound, Message: "instructor not found"}
+	ErrRoomNotFound       = &Error{Code: "room_not_found", Kind: KindNotFound, Message: "room not found"}
+)
+
+// CreateInstructor adds an instructor who can be given classes
+// input name, email
+// output instructor, error
+func (p *ClassProcessor) CreateInstructor(name, email string) (structs.Instructor, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	return p.resources.CreateInstructor(structs.Instructor{Name: strings.TrimSpace(name), Email: email})
+}
+
+// ListInstructors returns every instructor
+func (p *ClassProcessor) ListInstructors() ([]structs.Instructor, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	instructors, err := p.resources.ListInstructors()
+	if instructors == nil {
+		instructors = []structs.Instructor{}
+	}
+	return instructors, err
+}
+
+// GetInstructor returns the instructor with the id
+func (p *ClassProcessor) GetInstructor(id int) (structs.Instructor, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	instructor, err := p.resources.GetInstructor(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Instructor{}, ErrInstructorNotFound
+	}
+	return instructor, err
+}
+
+// CreateRoom adds a room which can host classes
+// input name
+// output room, error
+func (p *ClassProcessor) CreateRoom(name string) (structs.Room, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	return p.resources.CreateRoom(structs.Room{Name: strings.TrimSpace(name)})
+}
+
+// ListRooms returns every room
+func (p *ClassProcessor) ListRooms() ([]structs.Room, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	rooms, err := p.resources.ListRooms()
+	if rooms == nil {
+		rooms = []structs.Room{}
+	}
+	return rooms, err
+}
+
+// GetRoom returns the room with the id
+func (p *ClassProcessor) GetRoom(id int) (structs.Room, error) {
+
+	defer p.mu.RUnlock()
+	p.mu.RLock()
+
+	room, err := p.resources.GetRoom(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Room{}, ErrRoomNotFound
+	}
+	return room, err
+}
+
+// checkResources returns ErrInstructorNotFound or ErrRoomNotFound when the class is given
+// an instructor or room which does not exist, caller must hold p.mu
+func (p *ClassProcessor) checkResources(class structs.Class) error {
+	if class.InstructorID != 0 {
+		_, err := p.resources.GetInstructor(class.InstructorID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrInstructorNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if class.RoomID != 0 {
+		_, err := p.resources.GetRoom(class.RoomID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return ErrRoomNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
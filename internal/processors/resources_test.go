@@ -0,0 +1,75 @@
+package processors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestCreateClass_Resources(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classProcessor := NewClassProcessor(store, store, store)
+
+	instructor, _ := classProcessor.CreateInstructor(" Priya ", "priya@example.com")
+	room, _ := classProcessor.CreateRoom("Studio A")
+	if instructor.Name != "Priya" || room.ID == 0 {
+		t.Fatalf("expected instructor Priya and a room id, got %v, %v", instructor, room)
+	}
+	if _, err := classProcessor.GetInstructor(99); err != ErrInstructorNotFound {
+		t.Fatalf("expected %v, got %v", ErrInstructorNotFound, err)
+	}
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	if _, err := classProcessor.CreateClass("yoga", classDate, classDate, 10, nil, nil, 99, 0); err != ErrInstructorNotFound {
+		t.Fatalf("expected %v, got %v", ErrInstructorNotFound, err)
+	}
+	if _, err := classProcessor.CreateClass("yoga", classDate, classDate, 10, nil, nil, 0, 99); err != ErrRoomNotFound {
+		t.Fatalf("expected %v, got %v", ErrRoomNotFound, err)
+	}
+
+	morning := &structs.Schedule{StartTimes: []string{"07:00"}, Duration: 60}
+	yoga, err := classProcessor.CreateClass("yoga", classDate, classDate, 10, morning, nil, instructor.ID, room.ID)
+	if err != nil || yoga.InstructorID != instructor.ID || yoga.RoomID != room.ID {
+		t.Fatalf("expected yoga with instructor %d in room %d, got %v, %v", instructor.ID, room.ID, yoga, err)
+	}
+
+	// Classes with other names clash on a shared instructor or room, the error names the class
+	overlapping := &structs.Schedule{StartTimes: []string{"07:30"}, Duration: 60}
+	tests := []struct {
+		name         string
+		instructorID int
+		roomID       int
+		shared       string
+	}{
+		{"same instructor", instructor.ID, 0, "instructor 1"},
+		{"same room", 0, room.ID, "room 1"},
+		{"same instructor and room", instructor.ID, room.ID, "instructor 1 and room 1"},
+	}
+	for _, test := range tests {
+		_, err := classProcessor.CreateClass("spin", classDate, classDate, 10, overlapping, nil, test.instructorID, test.roomID)
+		if !errors.Is(err, ErrClassConflict) || !strings.Contains(err.Error(), `class "yoga" (id 1) has the same `+test.shared+" at 2025-03-03 07:00") {
+			t.Errorf("%s: expected a conflict with yoga, got %v", test.name, err)
+		}
+	}
+
+	// Without shared resources, or at other times, classes do not clash
+	if _, err := classProcessor.CreateClass("spin", classDate, classDate, 10, overlapping, nil, 0, 0); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	evening := &structs.Schedule{StartTimes: []string{"18:00"}, Duration: 60}
+	pilates, err := classProcessor.CreateClass("pilates", classDate, classDate, 10, evening, nil, instructor.ID, room.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Moving a class into a shared slot is a conflict too
+	extended := classDate.AddDate(0, 0, 1)
+	classProcessor.CreateClass("barre", extended, extended, 10, evening, nil, 0, room.ID)
+	if _, err := classProcessor.UpdateClass(pilates.ID, nil, &extended, nil); !errors.Is(err, ErrClassConflict) {
+		t.Fatalf("expected %v, got %v", ErrClassConflict, err)
+	}
+}
@@ -0,0 +1,202 @@
+package processors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+	"github.com/teambition/rrule-go"
+)
+
+const TIMEFORMAT = "15:04"
+
+// ErrInvalidSchedule is returned when the weekdays, start times, duration or recurrence rule of a schedule are invalid
+var ErrInvalidSchedule = &Error{Code: "invalid_schedule", Kind: KindInvalid, Message: "class schedule is invalid"}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// validateSchedule checks that the schedule can produce sessions, a nil schedule is valid
+func validateSchedule(schedule *structs.Schedule) error {
+	if schedule == nil {
+		return nil
+	}
+
+	if len(schedule.StartTimes) == 0 {
+		return fmt.Errorf("%w: at least one start time is required", ErrInvalidSchedule)
+	}
+	for _, startTime := range schedule.StartTimes {
+		if _, err := time.Parse(TIMEFORMAT, startTime); err != nil {
+			return fmt.Errorf("%w: start time %q is not in HH:MM format", ErrInvalidSchedule, startTime)
+		}
+	}
+	if schedule.Duration < 1 || schedule.Duration > 24*60 {
+		return fmt.Errorf("%w: duration must be between 1 and 1440 minutes", ErrInvalidSchedule)
+	}
+	for _, weekday := range schedule.Weekdays {
+		if _, ok := weekdays[weekday]; !ok {
+			return fmt.Errorf("%w: unknown weekday %q", ErrInvalidSchedule, weekday)
+		}
+	}
+
+	if schedule.RRule != "" {
+		if len(schedule.Weekdays) > 0 {
+			return fmt.Errorf("%w: weekdays and rrule cannot be combined", ErrInvalidSchedule)
+		}
+		option, err := rrule.StrToROption(strings.TrimPrefix(schedule.RRule, "RRULE:"))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		//start times set the times of day, so a rule yields at most one session per start time and day
+		if option.Freq > rrule.DAILY {
+			return fmt.Errorf("%w: rrule must not repeat more often than daily", ErrInvalidSchedule)
+		}
+		if len(option.Byhour) > 0 || len(option.Byminute) > 0 || len(option.Bysecond) > 0 {
+			return fmt.Errorf("%w: rrule must not set times of day, use start_times", ErrInvalidSchedule)
+		}
+	}
+	return nil
+}
+
+// sessionEnd returns when the session of the class starting at start ends, the all day session
+// of a class without schedule ends at the next midnight
+func sessionEnd(class structs.Class, start time.Time) time.Time {
+	if class.Schedule == nil {
+		return start.AddDate(0, 0, 1)
+	}
+	return start.Add(time.Duration(class.Schedule.Duration) * time.Minute)
+}
+
+// sessionsBetween returns the start of every session of the class on the dates from-to, in order.
+// Dates outside the start and end date of the class have no sessions. Start times are wall clock
+// times in the location of the dates, so sessions keep their time of day across DST changes.
+func sessionsBetween(class structs.Class, from, to time.Time) ([]time.Time, error) {
+	if from.Before(class.StartDate) {
+		from = class.StartDate
+	}
+	if to.After(class.EndDate) {
+		to = class.EndDate
+	}
+
+	var sessions []time.Time
+	if class.Schedule == nil {
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			sessions = append(sessions, day)
+		}
+		return sessions, nil
+	}
+
+	for _, startTime := range class.Schedule.StartTimes {
+		timeOfDay, err := time.Parse(TIMEFORMAT, startTime)
+		if err != nil {
+			return nil, err
+		}
+
+		if class.Schedule.RRule != "" {
+			rule, err := recurrenceRule(class, timeOfDay)
+			if err != nil {
+				return nil, err
+			}
+			sessions = append(sessions, rule.Between(from, endOfDay(to), true)...)
+			continue
+		}
+
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if runsOn(class.Schedule, day.Weekday()) {
+				sessions = append(sessions, atTimeOfDay(day, timeOfDay))
+			}
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Before(sessions[j]) })
+	return sessions, nil
+}
+
+// hasSession reports whether a session of the class starts at the given time
+func hasSession(class structs.Class, at time.Time) (bool, error) {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	sessions, err := sessionsBetween(class, day, day)
+	if err != nil {
+		return false, err
+	}
+	for _, session := range sessions {
+		if session.Equal(at) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// firstOverlap returns the start of the first session of class a which runs at the same time
+// as a session of class b, ok is false when no sessions overlap
+func firstOverlap(a, b structs.Class) (start time.Time, ok bool, err error) {
+	from, to := a.StartDate, a.EndDate
+	if b.StartDate.After(from) {
+		from = b.StartDate
+	}
+	if b.EndDate.Before(to) {
+		to = b.EndDate
+	}
+
+	//sessions can run past midnight, so the days around the shared dates are compared too
+	aSessions, err := sessionsBetween(a, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	bSessions, err := sessionsBetween(b, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	for _, start := range aSessions {
+		//sessions of b end in the order they start, so the first one ending after start is the only candidate
+		i := sort.Search(len(bSessions), func(i int) bool { return sessionEnd(b, bSessions[i]).After(start) })
+		if i < len(bSessions) && bSessions[i].Before(sessionEnd(a, start)) {
+			return start, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// runsOn reports whether a schedule without recurrence rule has sessions on the weekday
+func runsOn(schedule *structs.Schedule, weekday time.Weekday) bool {
+	if len(schedule.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range schedule.Weekdays {
+		if weekdays[day] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// recurrenceRule builds the RRULE of the class for sessions starting at the time of day,
+// the rule starts on the start date of the class and never runs past its end date
+func recurrenceRule(class structs.Class, timeOfDay time.Time) (*rrule.RRule, error) {
+	option, err := rrule.StrToROption(strings.TrimPrefix(class.Schedule.RRule, "RRULE:"))
+	if err != nil {
+		return nil, err
+	}
+
+	option.Dtstart = atTimeOfDay(class.StartDate, timeOfDay)
+	until := endOfDay(class.EndDate)
+	if option.Until.IsZero() || option.Until.After(until) {
+		option.Until = until
+	}
+	return rrule.NewRRule(*option)
+}
+
+// atTimeOfDay returns the wall clock time of day on the date, in the location of the date
+func atTimeOfDay(date, timeOfDay time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), timeOfDay.Hour(), timeOfDay.Minute(), 0, 0, date.Location())
+}
+
+// endOfDay returns the last second of the date, in the location of the date
+func endOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+}
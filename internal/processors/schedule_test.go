@@ -0,0 +1,129 @@
+package processors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	invalid := []structs.Schedule{
+		{StartTimes: nil, Duration: 60},
+		{StartTimes: []string{"7am"}, Duration: 60},
+		{StartTimes: []string{"07:00"}, Duration: 0},
+		{StartTimes: []string{"07:00"}, Duration: 60, Weekdays: []string{"someday"}},
+		{StartTimes: []string{"07:00"}, Duration: 60, Weekdays: []string{"mon"}, RRule: "FREQ=WEEKLY"},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=SOMETIMES"},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=SECONDLY"},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=MINUTELY;INTERVAL=5"},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=HOURLY"},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=DAILY;BYHOUR=1,2,3"},
+	}
+	for _, schedule := range invalid {
+		if err := validateSchedule(&schedule); !errors.Is(err, ErrInvalidSchedule) {
+			t.Fatalf("expected %v for %+v, got %v", ErrInvalidSchedule, schedule, err)
+		}
+	}
+
+	valid := structs.Schedule{StartTimes: []string{"07:00", "18:30"}, Duration: 60, RRule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR"}
+	if err := validateSchedule(&valid); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestSessionsBetween(t *testing.T) {
+	// 2025-03-03 is a Monday
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-09")
+	weekly := structs.Class{ClassName: "yoga", StartDate: startDate, EndDate: endDate,
+		Schedule: &structs.Schedule{Weekdays: []string{"mon", "wed", "fri"}, StartTimes: []string{"18:30", "07:00"}, Duration: 60}}
+	rule := weekly
+	rule.Schedule = &structs.Schedule{StartTimes: []string{"18:30", "07:00"}, Duration: 60, RRule: "FREQ=WEEKLY;BYDAY=MO,WE,FR"}
+
+	for _, class := range []structs.Class{weekly, rule} {
+		sessions, err := sessionsBetween(class, startDate, endDate)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		expected := []string{"2025-03-03T07:00", "2025-03-03T18:30", "2025-03-05T07:00", "2025-03-05T18:30", "2025-03-07T07:00", "2025-03-07T18:30"}
+		if len(sessions) != len(expected) {
+			t.Fatalf("expected %d sessions, got %v", len(expected), sessions)
+		}
+		for i, session := range sessions {
+			if session.Format("2006-01-02T15:04") != expected[i] {
+				t.Fatalf("expected session %s, got %s", expected[i], session)
+			}
+		}
+
+		tuesday, _ := time.Parse("2006-01-02T15:04", "2025-03-04T07:00")
+		if scheduled, _ := hasSession(class, tuesday); scheduled {
+			t.Fatalf("expected no session on tuesday")
+		}
+		if scheduled, _ := hasSession(class, tuesday.AddDate(0, 0, 1)); !scheduled {
+			t.Fatalf("expected a session on wednesday")
+		}
+	}
+
+	// A class without schedule runs one all day session per date
+	daily := structs.Class{ClassName: "spin", StartDate: startDate, EndDate: endDate}
+	if sessions, _ := sessionsBetween(daily, startDate.AddDate(0, 0, -3), endDate.AddDate(0, 0, 3)); len(sessions) != 7 {
+		t.Fatalf("expected 7 sessions, got %v", sessions)
+	}
+}
+
+func TestFirstOverlap(t *testing.T) {
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-03-31")
+	class := func(weekdays []string, startTime string, duration int) structs.Class {
+		return structs.Class{ClassName: "yoga", StartDate: startDate, EndDate: endDate,
+			Schedule: &structs.Schedule{Weekdays: weekdays, StartTimes: []string{startTime}, Duration: duration}}
+	}
+
+	tests := []struct {
+		name    string
+		a, b    structs.Class
+		overlap bool
+	}{
+		{"different weekdays", class([]string{"mon"}, "07:00", 60), class([]string{"tue"}, "07:00", 60), false},
+		{"back to back", class([]string{"mon"}, "07:00", 60), class([]string{"mon"}, "08:00", 60), false},
+		{"overlapping times", class([]string{"mon"}, "07:00", 60), class([]string{"mon"}, "07:30", 60), true},
+		{"past midnight", class([]string{"mon"}, "23:30", 60), class([]string{"tue"}, "00:00", 30), true},
+		{"all day session", structs.Class{ClassName: "yoga", StartDate: endDate, EndDate: endDate}, class(nil, "12:00", 60), true},
+	}
+	for _, test := range tests {
+		for _, pair := range [][2]structs.Class{{test.a, test.b}, {test.b, test.a}} {
+			_, overlap, err := firstOverlap(pair[0], pair[1])
+			if err != nil || overlap != test.overlap {
+				t.Fatalf("%s: expected overlap %v, got %v, %v", test.name, test.overlap, overlap, err)
+			}
+		}
+	}
+}
+
+func TestSessionsBetween_DST(t *testing.T) {
+	dublin, err := time.LoadLocation("Europe/Dublin")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Clocks in Dublin move forward on 2025-03-30
+	startDate, _ := time.ParseInLocation(DATEFORMAT, "2025-03-28", dublin)
+	endDate, _ := time.ParseInLocation(DATEFORMAT, "2025-04-01", dublin)
+	for _, schedule := range []*structs.Schedule{
+		{StartTimes: []string{"07:00"}, Duration: 60},
+		{StartTimes: []string{"07:00"}, Duration: 60, RRule: "FREQ=DAILY"},
+	} {
+		class := structs.Class{ClassName: "yoga", StartDate: startDate, EndDate: endDate, Schedule: schedule}
+		sessions, err := sessionsBetween(class, startDate, endDate)
+		if err != nil || len(sessions) != 5 {
+			t.Fatalf("expected 5 sessions, got %v, %v", sessions, err)
+		}
+		for _, session := range sessions {
+			if local := session.In(dublin); local.Hour() != 7 || local.Minute() != 0 {
+				t.Fatalf("expected sessions at 07:00 Dublin time, got %s", local)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// DefaultStudioID is the studio served by the routes without a studio prefix
+const DefaultStudioID = 1
+
+var (
+	ErrStudioNotFound  = &Error{Code: "studio_not_found", Kind: KindNotFound, Message: "studio not found"}
+	ErrInvalidTimezone = &Error{Code: "invalid_timezone", Kind: KindInvalid, Message: "timezone is not a valid IANA timezone"}
+)
+
+// StoreOpener opens the store holding the classes, bookings and members of the studio,
+// dates in the store are wall clock times of location
+type StoreOpener func(studio structs.Studio, location *time.Location) (storage.Store, error)
+
+// StudioProcessors are the processors of a single studio. Every studio has its own store
+// and processors, so class names, overlap checks and bookings never cross studios.
+type StudioProcessors struct {
+	Studio   structs.Studio
+	Location *time.Location
+	Classes  *ClassProcessor
+	Bookings *BookingProcessor
+	Members  *MemberProcessor
+
+	store storage.Store
+}
+
+// StudioProcessor implements the studio registry and hands out the processors of each studio
+type StudioProcessor struct {
+	studios storage.StudioRepository
+	open    StoreOpener
+
+	//mu guards the registry, the booking rules and the processors of the opened studios
+	mu     sync.Mutex
+	opened map[int]*StudioProcessors
+	rules  BookingRules
+}
+
+// NewStudioProcessor creates a studio processor which keeps studios in the repository and
+// opens the store of a studio with open the first time it is used
+func NewStudioProcessor(studios storage.StudioRepository, open StoreOpener) *StudioProcessor {
+	return &StudioProcessor{studios: studios, open: open, opened: make(map[int]*StudioProcessors)}
+}
+
+// SetupDefaultStudio registers the default studio when the registry is empty, an existing
+// default studio is moved to the timezone
+// input name, timezone
+// output default studio, error
+func (p *StudioProcessor) SetupDefaultStudio(name string, timezone string) (structs.Studio, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return structs.Studio{}, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+	}
+
+	studio, err := p.studios.GetStudio(DefaultStudioID)
+	if errors.Is(err, storage.ErrNotFound) {
+		studio, err = p.studios.CreateStudio(structs.Studio{Name: name, Timezone: timezone})
+		if err == nil && studio.ID != DefaultStudioID {
+			err = fmt.Errorf("default studio was registered with id %d", studio.ID)
+		}
+		return studio, err
+	}
+	if err != nil || studio.Timezone == timezone {
+		return studio, err
+	}
+
+	studio.Timezone = timezone
+	delete(p.opened, studio.ID)
+	return studio, p.studios.UpdateStudio(studio)
+}
+
+// SetBookingRules changes the booking rules of every studio
+func (p *StudioProcessor) SetBookingRules(rules BookingRules) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	p.rules = rules
+	for _, studio := range p.opened {
+		studio.Bookings.SetRules(rules)
+	}
+}
+
+// CreateStudio registers a new studio
+// input name, timezone
+// output studio, error
+func (p *StudioProcessor) CreateStudio(name, timezone string) (structs.Studio, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return structs.Studio{}, fmt.Errorf("%w: %s", ErrInvalidTimezone, timezone)
+	}
+	return p.studios.CreateStudio(structs.Studio{Name: name, Timezone: timezone})
+}
+
+// ListStudios returns every registered studio
+func (p *StudioProcessor) ListStudios() ([]structs.Studio, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	studios, err := p.studios.ListStudios()
+	if studios == nil && err == nil {
+		studios = []structs.Studio{}
+	}
+	return studios, err
+}
+
+// GetStudio returns the studio with the id
+func (p *StudioProcessor) GetStudio(id int) (structs.Studio, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	return p.getStudio(id)
+}
+
+// Processors returns the processors of the studio, its store is opened on first use
+// input studio id
+// output processors of the studio, error
+func (p *StudioProcessor) Processors(id int) (*StudioProcessors, error) {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	if processors, ok := p.opened[id]; ok {
+		return processors, nil
+	}
+
+	studio, err := p.getStudio(id)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(studio.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, studio.Timezone)
+	}
+
+	store, err := p.open(studio, location)
+	if err != nil {
+		return nil, err
+	}
+
+	classes := NewClassProcessor(store, store, store)
+	members := NewMemberProcessor(store)
+	bookings := NewBookingProcessor(classes, members, store)
+	bookings.SetRules(p.rules)
+	processors := &StudioProcessors{
+		Studio:   studio,
+		Location: location,
+		Classes:  classes,
+		Bookings: bookings,
+		Members:  members,
+		store:    store,
+	}
+	p.opened[id] = processors
+	return processors, nil
+}
+
+// Opened returns the processors of the studios whose store is open, ordered by studio id,
+// without opening any store
+func (p *StudioProcessor) Opened() []*StudioProcessors {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	opened := make([]*StudioProcessors, 0, len(p.opened))
+	for _, processors := range p.opened {
+		opened = append(opened, processors)
+	}
+	sort.Slice(opened, func(i, j int) bool { return opened[i].Studio.ID < opened[j].Studio.ID })
+	return opened
+}
+
+// Close closes the stores of the opened studios, later requests open them again. The store
+// of the default studio also holds the studio registry, it is left open for the caller which
+// opened it to close.
+func (p *StudioProcessor) Close() error {
+
+	defer p.mu.Unlock()
+	p.mu.Lock()
+
+	var errs []error
+	for id, studio := range p.opened {
+		if id == DefaultStudioID {
+			continue
+		}
+		//a booking in progress finishes before its store is closed
+		studio.Classes.mu.Lock()
+		errs = append(errs, studio.store.Close())
+		studio.Classes.mu.Unlock()
+		delete(p.opened, id)
+	}
+	return errors.Join(errs...)
+}
+
+// getStudio returns the studio with the id, caller must hold p.mu
+func (p *StudioProcessor) getStudio(id int) (structs.Studio, error) {
+	studio, err := p.studios.GetStudio(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return structs.Studio{}, ErrStudioNotFound
+	}
+	return studio, err
+}
@@ -0,0 +1,166 @@
+package processors
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/storage"
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// newTestStudioProcessor creates a studio processor which keeps every studio in memory
+func newTestStudioProcessor() *StudioProcessor {
+	return NewStudioProcessor(storage.NewMemoryStore(), func(structs.Studio, *time.Location) (storage.Store, error) {
+		return storage.NewMemoryStore(), nil
+	})
+}
+
+func TestSetupDefaultStudio(t *testing.T) {
+	studioProcessor := newTestStudioProcessor()
+
+	studio, err := studioProcessor.SetupDefaultStudio("default", "UTC")
+	if err != nil || studio.ID != DefaultStudioID {
+		t.Fatalf("expected default studio %d, got %v, %v", DefaultStudioID, studio, err)
+	}
+
+	// Running the setup again moves the default studio to the new timezone
+	if studio, err = studioProcessor.SetupDefaultStudio("default", "Europe/Dublin"); err != nil || studio.ID != DefaultStudioID {
+		t.Fatalf("expected default studio %d, got %v, %v", DefaultStudioID, studio, err)
+	}
+	processors, err := studioProcessor.Processors(DefaultStudioID)
+	if err != nil || processors.Location.String() != "Europe/Dublin" {
+		t.Fatalf("expected the default studio in Europe/Dublin, got %v, %v", processors, err)
+	}
+
+	if _, err := studioProcessor.SetupDefaultStudio("default", "Mars/Olympus"); !errors.Is(err, ErrInvalidTimezone) {
+		t.Fatalf("expected %v, got %v", ErrInvalidTimezone, err)
+	}
+}
+
+func TestCreateStudio(t *testing.T) {
+	studioProcessor := newTestStudioProcessor()
+
+	studio, err := studioProcessor.CreateStudio("Dublin", "Europe/Dublin")
+	if err != nil || studio.ID == 0 {
+		t.Fatalf("expected studio with an id, got %v, %v", studio, err)
+	}
+	if _, err := studioProcessor.CreateStudio("Nowhere", "Mars/Olympus"); !errors.Is(err, ErrInvalidTimezone) {
+		t.Fatalf("expected %v, got %v", ErrInvalidTimezone, err)
+	}
+
+	if _, err := studioProcessor.Processors(99); err != ErrStudioNotFound {
+		t.Fatalf("expected %v, got %v", ErrStudioNotFound, err)
+	}
+	if studios, _ := studioProcessor.ListStudios(); len(studios) != 1 || studios[0].Name != "Dublin" {
+		t.Fatalf("expected the Dublin studio, got %v", studios)
+	}
+}
+
+func TestStudios_Isolated(t *testing.T) {
+	studioProcessor := newTestStudioProcessor()
+	dublin, _ := studioProcessor.CreateStudio("Dublin", "Europe/Dublin")
+	london, _ := studioProcessor.CreateStudio("London", "Europe/London")
+	first, _ := studioProcessor.Processors(dublin.ID)
+	second, _ := studioProcessor.Processors(london.ID)
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-05")
+
+	// The same class on the same dates never clashes with the other studio's class
+	for _, studio := range []*StudioProcessors{first, second} {
+		if _, err := studio.Classes.CreateClass("yoga", classDate, classDate, 1, nil, nil, 0, 0); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := first.Classes.CreateClass("yoga", classDate, classDate, 1, nil, nil, 0, 0); !errors.Is(err, ErrClassConflict) {
+		t.Fatalf("expected %v, got %v", ErrClassConflict, err)
+	}
+
+	// Bookings are looked up within the studio only
+	booking, err := first.Bookings.BookClass("yoga", "Sai Kumar", classDate)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := second.Bookings.BookClass("yoga", "Sai Kumar", classDate); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := first.Bookings.BookClass("yoga", "John", classDate); err != ErrClassFull {
+		t.Fatalf("expected %v, got %v", ErrClassFull, err)
+	}
+
+	if page, _ := second.Bookings.ListBookings(BookingQuery{From: classDate, To: classDate, ClassName: "yoga"}); len(page.Bookings) != 1 {
+		t.Fatalf("expected one yoga booking in the second studio, got %v", page.Bookings)
+	}
+	if found, err := second.Bookings.GetBooking(booking.ID); err != nil || found.ClassName != "yoga" {
+		t.Fatalf("expected the second studio's own booking, got %v, %v", found, err)
+	}
+	if _, err := first.Bookings.CancelBookingByID(booking.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bookings, _ := second.Bookings.GetMemberBookings("Sai Kumar"); len(bookings) != 1 {
+		t.Fatalf("expected the second studio's booking to remain, got %v", bookings)
+	}
+}
+
+// closingStore counts how often it is closed
+type closingStore struct {
+	*storage.MemoryStore
+	closed int
+}
+
+func (s *closingStore) Close() error {
+	s.closed++
+	return nil
+}
+
+func TestStudioProcessor_Close(t *testing.T) {
+	var stores []*closingStore
+	studioProcessor := NewStudioProcessor(storage.NewMemoryStore(), func(structs.Studio, *time.Location) (storage.Store, error) {
+		store := &closingStore{MemoryStore: storage.NewMemoryStore()}
+		stores = append(stores, store)
+		return store, nil
+	})
+	studio, _ := studioProcessor.SetupDefaultStudio("default", "UTC")
+	dublin, _ := studioProcessor.CreateStudio("Dublin", "Europe/Dublin")
+	london, _ := studioProcessor.CreateStudio("London", "Europe/London")
+	studioProcessor.Processors(studio.ID)
+	studioProcessor.Processors(dublin.ID)
+	studioProcessor.Processors(london.ID)
+
+	if err := studioProcessor.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(stores) != 3 || stores[1].closed != 1 || stores[2].closed != 1 {
+		t.Fatalf("expected both studio stores closed once, got %v", stores)
+	}
+
+	// The store of the default studio is left to its owner
+	if stores[0].closed != 0 {
+		t.Fatalf("expected the default studio store to stay open, got %d closes", stores[0].closed)
+	}
+	if _, err := studioProcessor.Processors(studio.ID); err != nil || len(stores) != 3 {
+		t.Fatalf("expected the default studio to stay opened, got %v, %d stores", err, len(stores))
+	}
+
+	// A request after closing opens the studio again
+	if _, err := studioProcessor.Processors(dublin.ID); err != nil || len(stores) != 4 {
+		t.Fatalf("expected the studio to be opened again, got %v, %d stores", err, len(stores))
+	}
+}
+
+func TestStudioProcessor_SetBookingRules(t *testing.T) {
+	studioProcessor := newTestStudioProcessor()
+	first, _ := studioProcessor.CreateStudio("first", "UTC")
+	second, _ := studioProcessor.CreateStudio("second", "UTC")
+
+	// The rules apply to opened studios and to studios opened later
+	opened, _ := studioProcessor.Processors(first.ID)
+	studioProcessor.SetBookingRules(BookingRules{MaxStrikes: 2})
+	later, _ := studioProcessor.Processors(second.ID)
+
+	for _, processors := range []*StudioProcessors{opened, later} {
+		if processors.Bookings.rules.MaxStrikes != 2 {
+			t.Errorf("expected 2 strikes in studio %d, got %v", processors.Studio.ID, processors.Bookings.rules)
+		}
+	}
+}
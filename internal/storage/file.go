@@ -0,0 +1,350 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// FileStore keeps the data in memory and writes a JSON snapshot to disk after
+// every change, so classes and bookings survive a restart. A change which cannot
+// be written is undone in memory too.
+type FileStore struct {
+	*MemoryStore
+
+	path     string
+	location *time.Location
+
+	//writeMu serializes every change with its save, saved is the last snapshot written to disk
+	writeMu sync.Mutex
+	saved   []byte
+}
+
+// fileData is the JSON document written to disk
+type fileData struct {
+	ClassID   int                                     `json:"class_id"`
+	BookingID int                                     `json:"booking_id"`
+	Classes   []structs.Class                         `json:"classes"`
+	Bookings  map[string]map[string][]structs.Booking `json:"bookings"`
+	Waitlist  map[string]map[string][]structs.Booking `json:"waitlist"`
+	Cancelled []structs.Booking                       `json:"cancelled,omitempty"`
+	MemberID  int                                     `json:"member_id"`
+	Members   []structs.Member                        `json:"members"`
+	StudioID  int                                     `json:"studio_id,omitempty"`
+	Studios   []structs.Studio                        `json:"studios,omitempty"`
+
+	InstructorID int                  `json:"instructor_id,omitempty"`
+	Instructors  []structs.Instructor `json:"instructors,omitempty"`
+	RoomID       int                  `json:"room_id,omitempty"`
+	Rooms        []structs.Room       `json:"rooms,omitempty"`
+}
+
+// NewFileStore loads the store from the file at path, a missing file starts an empty store.
+// Loaded dates are converted to location, the JSON only keeps their offset.
+func NewFileStore(path string, location *time.Location) (*FileStore, error) {
+	store := &FileStore{MemoryStore: NewMemoryStore(), path: path, location: location}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := store.load(content); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// load replaces the data of the memory store with the JSON snapshot in content, an empty
+// content empties the store
+func (s *FileStore) load(content []byte) error {
+	var data fileData
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &data); err != nil {
+			return err
+		}
+	}
+
+	store := NewMemoryStore()
+	for i := range data.Classes {
+		data.Classes[i].StartDate = data.Classes[i].StartDate.In(s.location)
+		data.Classes[i].EndDate = data.Classes[i].EndDate.In(s.location)
+	}
+	store.classes = data.Classes
+	if data.ClassID > 0 {
+		store.classID = data.ClassID
+	}
+	if data.Bookings != nil {
+		store.bookings = data.Bookings
+	}
+	if data.Waitlist != nil {
+		store.waitlist = data.Waitlist
+	}
+	if data.BookingID > 0 {
+		store.bookingID = data.BookingID
+	}
+	if data.MemberID > 0 {
+		store.memberID = data.MemberID
+	}
+	for _, member := range data.Members {
+		store.members[member.ID] = member
+	}
+	store.instructors = data.Instructors
+	if data.InstructorID > 0 {
+		store.instructorID = data.InstructorID
+	}
+	store.rooms = data.Rooms
+	if data.RoomID > 0 {
+		store.roomID = data.RoomID
+	}
+	store.studios = data.Studios
+	if data.StudioID > 0 {
+		store.studioID = data.StudioID
+	}
+
+	//rebuild the ID and member lookups in ID order, entries written before bookings had IDs are given one
+	indexed := data.Cancelled
+	for _, entries := range []map[string]map[string][]structs.Booking{store.bookings, store.waitlist} {
+		for _, classes := range entries {
+			for _, bookings := range classes {
+				for i := range bookings {
+					bookings[i].ClassDate = bookings[i].ClassDate.In(s.location)
+					bookings[i] = store.assignBookingID(bookings[i])
+					indexed = append(indexed, bookings[i])
+				}
+			}
+		}
+	}
+	sort.Slice(indexed, func(i, j int) bool { return indexed[i].ID < indexed[j].ID })
+	for _, booking := range indexed {
+		booking.ClassDate = booking.ClassDate.In(s.location)
+		store.index(booking)
+	}
+
+	s.mu.Lock()
+	s.classes, s.classID = store.classes, store.classID
+	s.bookings, s.waitlist, s.bookingID = store.bookings, store.waitlist, store.bookingID
+	s.bookingsByID, s.memberBookings = store.bookingsByID, store.memberBookings
+	s.members, s.memberID = store.members, store.memberID
+	s.instructors, s.instructorID = store.instructors, store.instructorID
+	s.rooms, s.roomID = store.rooms, store.roomID
+	s.studios, s.studioID = store.studios, store.studioID
+	s.mu.Unlock()
+
+	s.saved = content
+	return nil
+}
+
+// write applies the change to the memory store and saves it. When saving fails the memory
+// store is loaded again from the last saved snapshot, so a change is never kept in memory
+// without being on disk.
+func (s *FileStore) write(change func() error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := change(); err != nil {
+		return err
+	}
+	if err := s.save(); err != nil {
+		if loadErr := s.load(s.saved); loadErr != nil {
+			return errors.Join(err, loadErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) CreateClass(class structs.Class) (structs.Class, error) {
+	err := s.write(func() (err error) {
+		class, err = s.MemoryStore.CreateClass(class)
+		return err
+	})
+	return class, err
+}
+
+func (s *FileStore) UpdateClass(class structs.Class) error {
+	return s.write(func() error {
+		return s.MemoryStore.UpdateClass(class)
+	})
+}
+
+func (s *FileStore) DeleteClass(id int) error {
+	return s.write(func() error {
+		return s.MemoryStore.DeleteClass(id)
+	})
+}
+
+func (s *FileStore) AddBooking(booking structs.Booking) (structs.Booking, error) {
+	err := s.write(func() (err error) {
+		booking, err = s.MemoryStore.AddBooking(booking)
+		return err
+	})
+	return booking, err
+}
+
+func (s *FileStore) RemoveBooking(booking structs.Booking) error {
+	return s.write(func() error {
+		return s.MemoryStore.RemoveBooking(booking)
+	})
+}
+
+func (s *FileStore) UpdateBooking(booking structs.Booking) error {
+	return s.write(func() error {
+		return s.MemoryStore.UpdateBooking(booking)
+	})
+}
+
+func (s *FileStore) ApplyChange(change BookingChange) (added structs.Booking, err error) {
+	err = s.write(func() (err error) {
+		added, err = s.MemoryStore.ApplyChange(change)
+		return err
+	})
+	return added, err
+}
+
+func (s *FileStore) AddToWaitlist(booking structs.Booking) (structs.Booking, error) {
+	err := s.write(func() (err error) {
+		booking, err = s.MemoryStore.AddToWaitlist(booking)
+		return err
+	})
+	return booking, err
+}
+
+func (s *FileStore) RemoveFromWaitlist(booking structs.Booking) error {
+	return s.write(func() error {
+		return s.MemoryStore.RemoveFromWaitlist(booking)
+	})
+}
+
+func (s *FileStore) CreateMember(member structs.Member) (structs.Member, error) {
+	err := s.write(func() (err error) {
+		member, err = s.MemoryStore.CreateMember(member)
+		return err
+	})
+	return member, err
+}
+
+func (s *FileStore) UpdateMember(member structs.Member) error {
+	return s.write(func() error {
+		return s.MemoryStore.UpdateMember(member)
+	})
+}
+
+func (s *FileStore) CreateInstructor(instructor structs.Instructor) (structs.Instructor, error) {
+	err := s.write(func() (err error) {
+		instructor, err = s.MemoryStore.CreateInstructor(instructor)
+		return err
+	})
+	return instructor, err
+}
+
+func (s *FileStore) CreateRoom(room structs.Room) (structs.Room, error) {
+	err := s.write(func() (err error) {
+		room, err = s.MemoryStore.CreateRoom(room)
+		return err
+	})
+	return room, err
+}
+
+func (s *FileStore) CreateStudio(studio structs.Studio) (structs.Studio, error) {
+	err := s.write(func() (err error) {
+		studio, err = s.MemoryStore.CreateStudio(studio)
+		return err
+	})
+	return studio, err
+}
+
+func (s *FileStore) UpdateStudio(studio structs.Studio) error {
+	return s.write(func() error {
+		return s.MemoryStore.UpdateStudio(studio)
+	})
+}
+
+// Check reports an error when the directory of the data file is gone, changes could not be saved
+func (s *FileStore) Check(ctx context.Context) error {
+	dir := filepath.Dir(s.path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
+
+// Close writes the current state to disk once more, every change is already saved when it is made
+func (s *FileStore) Close() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	return s.save()
+}
+
+// save writes the current state to a temporary file and renames it over the
+// data file, so a crash never leaves a partially written file behind. Caller must
+// hold s.writeMu.
+func (s *FileStore) save() error {
+	s.mu.RLock()
+	members := make([]structs.Member, 0, len(s.members))
+	for _, member := range s.members {
+		members = append(members, member)
+	}
+	var cancelled []structs.Booking
+	for _, booking := range s.bookingsByID {
+		if structs.IsCancelled(booking.Status) {
+			cancelled = append(cancelled, booking)
+		}
+	}
+	sort.Slice(cancelled, func(i, j int) bool { return cancelled[i].ID < cancelled[j].ID })
+	content, err := json.Marshal(fileData{
+		ClassID:   s.classID,
+		BookingID: s.bookingID,
+		Classes:   s.classes,
+		Bookings:  s.bookings,
+		Waitlist:  s.waitlist,
+		Cancelled: cancelled,
+		MemberID:  s.memberID,
+		Members:   members,
+		StudioID:  s.studioID,
+		Studios:   s.studios,
+
+		InstructorID: s.instructorID,
+		Instructors:  s.instructors,
+		RoomID:       s.roomID,
+		Rooms:        s.rooms,
+	})
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.saved = content
+	return nil
+}
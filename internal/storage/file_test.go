@@ -0,0 +1,527 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+func TestFileStore_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	store, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	class, err := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate, Capacity: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	booked, _ := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate})
+	waiting, _ := store.AddToWaitlist(structs.Booking{MemberName: "John", ClassName: "yoga", ClassDate: classDate})
+
+	// Reopen the file as a restarted server would
+	reloaded, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	classes, _ := reloaded.ListClasses()
+	if len(classes) != 1 || classes[0].ID != class.ID {
+		t.Fatalf("expected class %d to be reloaded, got %v", class.ID, classes)
+	}
+
+	bookings, _ := reloaded.ListBookings("yoga", classDate)
+	if len(bookings) != 1 || bookings[0].MemberName != "Sai Kumar" {
+		t.Fatalf("expected booking of Sai Kumar to be reloaded, got %v", bookings)
+	}
+
+	waitlist, _ := reloaded.ListWaitlist("yoga", classDate)
+	if len(waitlist) != 1 || waitlist[0].MemberName != "John" {
+		t.Fatalf("expected John on the reloaded waitlist, got %v", waitlist)
+	}
+
+	// Lookups by id and member are rebuilt and ids keep increasing
+	if found, err := reloaded.GetBooking(waiting.ID); err != nil || found.MemberName != "John" {
+		t.Fatalf("expected waitlist entry %d of John, got %v, %v", waiting.ID, found, err)
+	}
+	if memberBookings, _ := reloaded.ListBookingsByMember("Sai Kumar"); len(memberBookings) != 1 || memberBookings[0].ID != booked.ID {
+		t.Fatalf("expected booking %d of Sai Kumar, got %v", booked.ID, memberBookings)
+	}
+	if next, _ := reloaded.AddBooking(structs.Booking{MemberName: "Jane", ClassName: "yoga", ClassDate: classDate}); next.ID != waiting.ID+1 {
+		t.Fatalf("expected booking id %d, got %d", waiting.ID+1, next.ID)
+	}
+
+	// IDs keep increasing after a restart
+	next, _ := reloaded.CreateClass(structs.Class{ClassName: "pilates", StartDate: classDate, EndDate: classDate, Capacity: 1})
+	if next.ID != class.ID+1 {
+		t.Fatalf("expected class id %d, got %d", class.ID+1, next.ID)
+	}
+}
+
+func TestMemoryStore_RemoveMissingBooking(t *testing.T) {
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	err := NewMemoryStore().RemoveBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate})
+	if err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestFileStore_ReloadLocation(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Dublin")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "data.json")
+	classDate := time.Date(2025, 3, 28, 0, 0, 0, 0, location)
+
+	store, _ := NewFileStore(path, location)
+	store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate.AddDate(0, 0, 5), Capacity: 1})
+	store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate})
+
+	// The JSON only keeps the winter offset, reloaded dates are in the studio's location again
+	reloaded, err := NewFileStore(path, location)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	classes, _ := reloaded.ListClasses()
+	if classes[0].StartDate.Location() != location || classes[0].EndDate.Location() != location {
+		t.Fatalf("expected class dates in %s, got %v", location, classes[0])
+	}
+	if bookings, _ := reloaded.ListBookings("yoga", classDate); len(bookings) != 1 || bookings[0].ClassDate.Location() != location {
+		t.Fatalf("expected booking dates in %s, got %v", location, bookings)
+	}
+}
+
+func TestFileStore_ReloadStudios(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	store, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	store.CreateStudio(structs.Studio{Name: "default", Timezone: "UTC"})
+	dublin, _ := store.CreateStudio(structs.Studio{Name: "Dublin", Timezone: "Europe/Dublin"})
+
+	reloaded, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, err := reloaded.GetStudio(dublin.ID); err != nil || found.Name != "Dublin" {
+		t.Fatalf("expected studio %d of Dublin, got %v, %v", dublin.ID, found, err)
+	}
+	if next, _ := reloaded.CreateStudio(structs.Studio{Name: "London", Timezone: "Europe/London"}); next.ID != dublin.ID+1 {
+		t.Fatalf("expected studio id %d, got %d", dublin.ID+1, next.ID)
+	}
+}
+
+func TestFileStore_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	store, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	store.CreateStudio(structs.Studio{Name: "default", Timezone: "UTC"})
+
+	// Closing flushes the data file, closing again does nothing more
+	if err := store.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	reloaded, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if studios, _ := reloaded.ListStudios(); len(studios) != 1 {
+		t.Fatalf("expected one studio, got %v", studios)
+	}
+}
+
+func TestFileStore_Check(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	os.Mkdir(dir, 0o755)
+
+	store, err := NewFileStore(filepath.Join(dir, "data.json"), time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.Check(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Changes cannot be saved once the directory of the data file is gone
+	os.RemoveAll(dir)
+	if err := store.Check(context.Background()); err == nil {
+		t.Fatalf("expected an error without the data directory")
+	}
+}
+
+func TestFileStore_SaveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	os.Mkdir(dir, 0o755)
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	store, err := NewFileStore(filepath.Join(dir, "data.json"), time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	class, _ := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate, Capacity: 10})
+
+	// A change which cannot be saved is not kept in memory either
+	os.RemoveAll(dir)
+	if _, err := store.CreateClass(structs.Class{ClassName: "spin", StartDate: classDate, EndDate: classDate, Capacity: 5}); err == nil {
+		t.Fatalf("expected an error without the data directory")
+	}
+	class.Capacity = 20
+	if err := store.UpdateClass(class); err == nil {
+		t.Fatalf("expected an error without the data directory")
+	}
+	if _, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked}); err == nil {
+		t.Fatalf("expected an error without the data directory")
+	}
+	if classes, _ := store.ListClasses(); len(classes) != 1 || classes[0].Capacity != 10 {
+		t.Fatalf("expected the saved class only, got %v", classes)
+	}
+	if bookings, _ := store.ListBookingsByMember("Sai Kumar"); len(bookings) != 0 {
+		t.Fatalf("expected no bookings, got %v", bookings)
+	}
+
+	// IDs of changes which were not saved are handed out again
+	os.Mkdir(dir, 0o755)
+	if next, err := store.CreateClass(structs.Class{ClassName: "spin", StartDate: classDate, EndDate: classDate, Capacity: 5}); err != nil || next.ID != 2 {
+		t.Fatalf("expected class id 2, got %v, %v", next, err)
+	}
+}
+
+// checkQueryBookings checks the filters of QueryBookings on the store
+func checkQueryBookings(t *testing.T, store Store) {
+	t.Helper()
+
+	first, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	second := first.AddDate(0, 0, 1)
+	for _, booking := range []structs.Booking{
+		{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: first},
+		{MemberName: "John", ClassName: "yoga", ClassDate: first.Add(18 * time.Hour)},
+		{MemberName: "Sai Kumar", ClassName: "spin", ClassDate: second},
+	} {
+		booking.Status = structs.BookingStatusBooked
+		if _, err := store.AddBooking(booking); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	store.AddToWaitlist(structs.Booking{MemberName: "Jane", ClassName: "spin", ClassDate: second})
+
+	tests := []struct {
+		name   string
+		filter BookingFilter
+		count  int
+	}{
+		{"every booking but the waitlist", BookingFilter{}, 3},
+		{"from date", BookingFilter{From: second}, 1},
+		{"to date including its sessions", BookingFilter{To: first}, 2},
+		{"class", BookingFilter{ClassName: "yoga"}, 2},
+		{"member", BookingFilter{MemberName: "SAI KUMAR "}, 2},
+		{"every filter", BookingFilter{From: first, To: second, ClassName: "spin", MemberName: "Sai Kumar"}, 1},
+	}
+	for _, test := range tests {
+		if bookings, err := store.QueryBookings(test.filter); err != nil || len(bookings) != test.count {
+			t.Errorf("%s: expected %d bookings, got %v, %v", test.name, test.count, bookings, err)
+		}
+	}
+}
+
+func TestMemoryStore_QueryBookings(t *testing.T) {
+	checkQueryBookings(t, NewMemoryStore())
+}
+
+// checkAttendance checks that bookings with attendance still hold their spot and that the
+// cancellation policy of classes and the strikes of members are kept
+func checkAttendance(t *testing.T, store Store) {
+	t.Helper()
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	class, err := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate, Capacity: 2,
+		CancellationPolicy: &structs.CancellationPolicy{LateCancelHours: 12}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetClass(class.ID); found.CancellationPolicy == nil || found.CancellationPolicy.LateCancelHours != 12 {
+		t.Fatalf("expected a late cancel window of 12 hours, got %v", found.CancellationPolicy)
+	}
+
+	booking, _ := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	waiting, _ := store.AddToWaitlist(structs.Booking{MemberName: "John", ClassName: "yoga", ClassDate: classDate})
+
+	booking.Status = structs.BookingStatusNoShow
+	if err := store.UpdateBooking(booking); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetBooking(booking.ID); found.Status != structs.BookingStatusNoShow {
+		t.Fatalf("expected status %s, got %v", structs.BookingStatusNoShow, found)
+	}
+	if bookings, _ := store.ListBookings("yoga", classDate); len(bookings) != 1 || bookings[0].Status != structs.BookingStatusNoShow {
+		t.Fatalf("expected the no-show to keep its spot, got %v", bookings)
+	}
+	if bookings, _ := store.QueryBookings(BookingFilter{}); len(bookings) != 1 {
+		t.Fatalf("expected the no-show to be a confirmed booking, got %v", bookings)
+	}
+
+	// Waitlist entries are not confirmed bookings
+	waiting.Status = structs.BookingStatusAttended
+	if err := store.UpdateBooking(waiting); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+	if err := store.RemoveBooking(booking); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	member, _ := store.CreateMember(structs.Member{Name: "Jane", Email: "jane@example.com"})
+	member.Strikes = 2
+	if err := store.UpdateMember(member); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetMember(member.ID); found.Strikes != 2 {
+		t.Fatalf("expected 2 strikes, got %v", found)
+	}
+}
+
+func TestMemoryStore_Attendance(t *testing.T) {
+	checkAttendance(t, NewMemoryStore())
+}
+
+// checkCancellations checks that cancelled bookings and waitlist entries leave their session but stay
+// in the booking history of their member, and that a cancellation is stored with the changes it causes
+func checkCancellations(t *testing.T, store Store) {
+	t.Helper()
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	booking, _ := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	waiting, _ := store.AddToWaitlist(structs.Booking{MemberName: "John", ClassName: "yoga", ClassDate: classDate})
+
+	booking.Status = structs.BookingStatusLateCancelled
+	waiting.Status = structs.BookingStatusCancelled
+	for _, cancelled := range []structs.Booking{booking, waiting} {
+		if err := store.UpdateBooking(cancelled); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found, _ := store.GetBooking(cancelled.ID); found.Status != cancelled.Status {
+			t.Fatalf("expected status %s, got %v", cancelled.Status, found)
+		}
+	}
+	if err := store.UpdateBooking(booking); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	if bookings, _ := store.ListBookings("yoga", classDate); len(bookings) != 0 {
+		t.Fatalf("expected no bookings, got %v", bookings)
+	}
+	if waitlist, _ := store.ListWaitlist("yoga", classDate); len(waitlist) != 0 {
+		t.Fatalf("expected an empty waitlist, got %v", waitlist)
+	}
+	if bookings, _ := store.QueryBookings(BookingFilter{}); len(bookings) != 0 {
+		t.Fatalf("expected no confirmed bookings, got %v", bookings)
+	}
+	if bookings, _ := store.ListBookingsByMember("sai kumar"); len(bookings) != 1 || bookings[0].Status != structs.BookingStatusLateCancelled {
+		t.Fatalf("expected the late cancelled booking of Sai Kumar, got %v", bookings)
+	}
+
+	// The member can book the session again
+	rebooked, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// A cancellation, the promotion of the first waitlist entry and the member update are stored together
+	first, _ := store.AddToWaitlist(structs.Booking{MemberName: "John", ClassName: "yoga", ClassDate: classDate})
+	second, _ := store.AddToWaitlist(structs.Booking{MemberName: "Jane", ClassName: "yoga", ClassDate: classDate})
+	member, err := store.CreateMember(structs.Member{Name: "Sai Kumar"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	member.Strikes = 1
+	rebooked.Status = structs.BookingStatusCancelled
+	first.Status = structs.BookingStatusBooked
+	change := BookingChange{Update: []structs.Booking{rebooked, first}, Members: []structs.Member{member}}
+	if _, err := store.ApplyChange(change); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bookings, _ := store.ListBookings("yoga", classDate); len(bookings) != 1 || bookings[0].ID != first.ID {
+		t.Fatalf("expected the promoted booking only, got %v", bookings)
+	}
+	if waitlist, _ := store.ListWaitlist("yoga", classDate); len(waitlist) != 1 || waitlist[0].ID != second.ID {
+		t.Fatalf("expected booking %d to wait, got %v", second.ID, waitlist)
+	}
+	if found, _ := store.GetMember(member.ID); found.Strikes != 1 {
+		t.Fatalf("expected the member to be updated, got %v", found)
+	}
+
+	// A change with a write which fails stores none of its writes
+	member.Strikes = 2
+	second.Status = structs.BookingStatusBooked
+	change = BookingChange{Add: &structs.Booking{MemberName: "Mary", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked},
+		Update: []structs.Booking{second, rebooked}, Members: []structs.Member{member}}
+	if _, err := store.ApplyChange(change); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+	if bookings, _ := store.ListBookings("yoga", classDate); len(bookings) != 1 || bookings[0].ID != first.ID {
+		t.Fatalf("expected the promoted booking only, got %v", bookings)
+	}
+	if waitlist, _ := store.ListWaitlist("yoga", classDate); len(waitlist) != 1 || waitlist[0].ID != second.ID {
+		t.Fatalf("expected booking %d to wait, got %v", second.ID, waitlist)
+	}
+	if found, _ := store.GetMember(member.ID); found.Strikes != 1 {
+		t.Fatalf("expected the member to be unchanged, got %v", found)
+	}
+
+	// A waitlist entry is added with the member paying for it
+	change = BookingChange{Add: &structs.Booking{MemberName: "Mary", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusWaitlisted},
+		Members: []structs.Member{member}}
+	added, err := store.ApplyChange(change)
+	if err != nil || added.ID == 0 {
+		t.Fatalf("expected the waitlist entry to be added, got %v, %v", added, err)
+	}
+	if waitlist, _ := store.ListWaitlist("yoga", classDate); len(waitlist) != 2 || waitlist[1].ID != added.ID {
+		t.Fatalf("expected booking %d to wait last, got %v", added.ID, waitlist)
+	}
+	if found, _ := store.GetMember(member.ID); found.Strikes != 2 {
+		t.Fatalf("expected the member to be updated, got %v", found)
+	}
+}
+
+func TestMemoryStore_Cancellations(t *testing.T) {
+	checkCancellations(t, NewMemoryStore())
+}
+
+func TestFileStore_ReloadCancellations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	store, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	checkCancellations(t, store)
+
+	reloaded, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bookings, _ := reloaded.ListBookingsByMember("Sai Kumar"); len(bookings) != 2 || bookings[0].Status != structs.BookingStatusLateCancelled ||
+		bookings[1].Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected the late cancelled booking to be reloaded, got %v", bookings)
+	}
+	if found, err := reloaded.GetBooking(2); err != nil || found.Status != structs.BookingStatusCancelled {
+		t.Fatalf("expected the cancelled waitlist entry to be reloaded, got %v, %v", found, err)
+	}
+}
+
+// checkMemberships checks that the membership of members and the credits used by bookings are kept
+func checkMemberships(t *testing.T, store Store) {
+	t.Helper()
+
+	expiresOn, _ := time.Parse(DATEFORMAT, "2025-03-31")
+	member, _ := store.CreateMember(structs.Member{Name: "Sai Kumar", Email: "sai@example.com"})
+	if found, _ := store.GetMember(member.ID); found.Membership != nil {
+		t.Fatalf("expected no membership, got %v", found.Membership)
+	}
+
+	member.Membership = &structs.Membership{Plan: structs.PlanPack, Credits: 4, ExpiresOn: &expiresOn, Used: map[string]int{"2025-03": 1}, Assignment: 2}
+	if err := store.UpdateMember(member); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	found, _ := store.FindMemberByName("Sai Kumar")
+	if found.Membership == nil || found.Membership.Plan != structs.PlanPack || found.Membership.Credits != 4 ||
+		!found.Membership.ExpiresOn.Equal(expiresOn) || found.Membership.Used["2025-03"] != 1 || found.Membership.Assignment != 2 {
+		t.Fatalf("expected a pack of 4 credits expiring on %v, got %v", expiresOn, found.Membership)
+	}
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	booking, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate,
+		Status: structs.BookingStatusBooked, CreditUsed: true, CreditAssignment: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetBooking(booking.ID); !found.CreditUsed || found.CreditAssignment != 2 {
+		t.Fatalf("expected booking %d to have used a credit, got %v", booking.ID, found)
+	}
+}
+
+func TestMemoryStore_Memberships(t *testing.T) {
+	checkMemberships(t, NewMemoryStore())
+}
+
+// checkResources checks that instructors and rooms are stored and that classes keep theirs
+func checkResources(t *testing.T, store Store) {
+	t.Helper()
+
+	instructor, err := store.CreateInstructor(structs.Instructor{Name: "Priya", Email: "priya@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	room, err := store.CreateRoom(structs.Room{Name: "Studio A"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	store.CreateRoom(structs.Room{Name: "Studio B"})
+
+	if found, err := store.GetInstructor(instructor.ID); err != nil || found != instructor {
+		t.Fatalf("expected instructor %v, got %v, %v", instructor, found, err)
+	}
+	if _, err := store.GetRoom(99); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+	if instructors, _ := store.ListInstructors(); len(instructors) != 1 {
+		t.Fatalf("expected one instructor, got %v", instructors)
+	}
+	if rooms, _ := store.ListRooms(); len(rooms) != 2 || rooms[0] != room {
+		t.Fatalf("expected rooms Studio A and Studio B, got %v", rooms)
+	}
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	class, _ := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate, Capacity: 1, InstructorID: instructor.ID})
+	if found, _ := store.GetClass(class.ID); found.InstructorID != instructor.ID || found.RoomID != 0 {
+		t.Fatalf("expected class of instructor %d without room, got %v", instructor.ID, found)
+	}
+
+	class.RoomID = room.ID
+	if err := store.UpdateClass(class); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetClass(class.ID); found.RoomID != room.ID {
+		t.Fatalf("expected class in room %d, got %v", room.ID, found)
+	}
+}
+
+func TestMemoryStore_Resources(t *testing.T) {
+	checkResources(t, NewMemoryStore())
+}
+
+func TestFileStore_ReloadResources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	store, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	checkResources(t, store)
+
+	reloaded, err := NewFileStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if instructors, _ := reloaded.ListInstructors(); len(instructors) != 1 || instructors[0].Name != "Priya" {
+		t.Fatalf("expected instructor Priya to be reloaded, got %v", instructors)
+	}
+	if next, _ := reloaded.CreateRoom(structs.Room{Name: "Studio C"}); next.ID != 3 {
+		t.Fatalf("expected room id 3, got %d", next.ID)
+	}
+}
@@ -0,0 +1,560 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// MemoryStore keeps classes, bookings, members, instructors, rooms and the studio registry in process memory, data is lost on restart
+type MemoryStore struct {
+	mu        sync.RWMutex
+	classes   []structs.Class
+	classID   int
+	bookingID int
+	bookings  map[string]map[string][]structs.Booking
+	waitlist  map[string]map[string][]structs.Booking
+
+	//lookups of bookings and waitlist entries by ID and by normalized member name,
+	//the date wise maps above stay the source of the booking order. Cancelled
+	//bookings are only kept here.
+	bookingsByID   map[int]structs.Booking
+	memberBookings map[string][]int
+
+	members  map[int]structs.Member
+	memberID int
+
+	instructors  []structs.Instructor
+	instructorID int
+	rooms        []structs.Room
+	roomID       int
+
+	studios  []structs.Studio
+	studioID int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		classID:        1,
+		bookingID:      1,
+		bookings:       make(map[string]map[string][]structs.Booking),
+		waitlist:       make(map[string]map[string][]structs.Booking),
+		bookingsByID:   make(map[int]structs.Booking),
+		memberBookings: make(map[string][]int),
+		members:        make(map[int]structs.Member),
+		memberID:       1,
+		instructorID:   1,
+		roomID:         1,
+		studioID:       1,
+	}
+}
+
+// Check always succeeds, the data is in the memory of the process
+func (s *MemoryStore) Check(ctx context.Context) error {
+	return nil
+}
+
+// Close does nothing, the data of a memory store is lost when the process exits
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) CreateClass(class structs.Class) (structs.Class, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	class.ID = s.classID
+	s.classID++
+	s.classes = append(s.classes, class)
+	return class, nil
+}
+
+func (s *MemoryStore) ListClasses() ([]structs.Class, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]structs.Class(nil), s.classes...), nil
+}
+
+func (s *MemoryStore) GetClass(id int) (structs.Class, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, class := range s.classes {
+		if class.ID == id {
+			return class, nil
+		}
+	}
+	return structs.Class{}, ErrNotFound
+}
+
+func (s *MemoryStore) UpdateClass(class structs.Class) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.classes {
+		if s.classes[i].ID == class.ID {
+			s.classes[i] = class
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) DeleteClass(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.classes {
+		if s.classes[i].ID == id {
+			s.classes = append(s.classes[:i:i], s.classes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) AddBooking(booking structs.Booking) (structs.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	booking = s.assignBookingID(booking)
+	appendEntry(s.bookings, booking)
+	s.index(booking)
+	return booking, nil
+}
+
+func (s *MemoryStore) RemoveBooking(booking structs.Booking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := removeEntry(s.bookings, booking); err != nil {
+		return err
+	}
+	s.unindex(booking.ID)
+	return nil
+}
+
+func (s *MemoryStore) UpdateBooking(booking structs.Booking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateEntry(booking)
+}
+
+func (s *MemoryStore) ApplyChange(change BookingChange) (structs.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	//every write is checked before the first one is made, so a failed change leaves the store as it was
+	for _, booking := range change.Update {
+		if _, err := s.updatedEntry(booking); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+	for _, member := range change.Members {
+		if _, ok := s.members[member.ID]; !ok {
+			return structs.Booking{}, ErrNotFound
+		}
+	}
+
+	var added structs.Booking
+	if change.Add != nil {
+		entries := s.bookings
+		if change.Add.Status == structs.BookingStatusWaitlisted {
+			entries = s.waitlist
+		}
+		added = s.assignBookingID(*change.Add)
+		appendEntry(entries, added)
+		s.index(added)
+	}
+	for _, booking := range change.Update {
+		if err := s.updateEntry(booking); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+	for _, member := range change.Members {
+		s.members[member.ID] = member
+	}
+	return added, nil
+}
+
+func (s *MemoryStore) GetBooking(id int) (structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	booking, ok := s.bookingsByID[id]
+	if !ok {
+		return structs.Booking{}, ErrNotFound
+	}
+	return booking, nil
+}
+
+func (s *MemoryStore) ListBookings(className string, classDate time.Time) ([]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sessionEntries(s.bookings, className, classDate), nil
+}
+
+func (s *MemoryStore) ListBookingsByDate(classDate time.Time) (map[string][]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make(map[string][]structs.Booking)
+	for className, bookings := range s.bookings[classDate.Format(DATEFORMAT)] {
+		if len(bookings) > 0 {
+			result[className] = append([]structs.Booking(nil), bookings...)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) ListBookingsByClass(className string) ([]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []structs.Booking
+	for _, classes := range s.bookings {
+		result = append(result, classes[className]...)
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) ListBookingsByMember(memberName string) ([]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member := structs.NormalizeMemberName(memberName)
+	result := make([]structs.Booking, 0, len(s.memberBookings[member]))
+	for _, id := range s.memberBookings[member] {
+		result = append(result, s.bookingsByID[id])
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) QueryBookings(filter BookingFilter) ([]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []structs.Booking
+	for date, classes := range s.bookings {
+		if (!filter.From.IsZero() && date < filter.From.Format(DATEFORMAT)) || (!filter.To.IsZero() && date > filter.To.Format(DATEFORMAT)) {
+			continue
+		}
+		for className, bookings := range classes {
+			if filter.ClassName != "" && className != filter.ClassName {
+				continue
+			}
+			for _, booking := range bookings {
+				if filter.MemberName == "" || structs.NormalizeMemberName(booking.MemberName) == structs.NormalizeMemberName(filter.MemberName) {
+					result = append(result, booking)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) AddToWaitlist(booking structs.Booking) (structs.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	booking = s.assignBookingID(booking)
+	booking.Status = structs.BookingStatusWaitlisted
+	appendEntry(s.waitlist, booking)
+	s.index(booking)
+	return booking, nil
+}
+
+func (s *MemoryStore) RemoveFromWaitlist(booking structs.Booking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := removeEntry(s.waitlist, booking); err != nil {
+		return err
+	}
+	s.unindex(booking.ID)
+	return nil
+}
+
+func (s *MemoryStore) ListWaitlist(className string, classDate time.Time) ([]structs.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sessionEntries(s.waitlist, className, classDate), nil
+}
+
+func (s *MemoryStore) CreateMember(member structs.Member) (structs.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member.ID = s.memberID
+	s.memberID++
+	s.members[member.ID] = member
+	return member, nil
+}
+
+func (s *MemoryStore) GetMember(id int) (structs.Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.members[id]
+	if !ok {
+		return structs.Member{}, ErrNotFound
+	}
+	return member, nil
+}
+
+func (s *MemoryStore) FindMemberByName(name string) (structs.Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, member := range s.members {
+		if structs.NormalizeMemberName(member.Name) == structs.NormalizeMemberName(name) {
+			return member, nil
+		}
+	}
+	return structs.Member{}, ErrNotFound
+}
+
+func (s *MemoryStore) UpdateMember(member structs.Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[member.ID]; !ok {
+		return ErrNotFound
+	}
+	s.members[member.ID] = member
+	return nil
+}
+
+func (s *MemoryStore) CreateInstructor(instructor structs.Instructor) (structs.Instructor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instructor.ID = s.instructorID
+	s.instructorID++
+	s.instructors = append(s.instructors, instructor)
+	return instructor, nil
+}
+
+func (s *MemoryStore) ListInstructors() ([]structs.Instructor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]structs.Instructor(nil), s.instructors...), nil
+}
+
+func (s *MemoryStore) GetInstructor(id int) (structs.Instructor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, instructor := range s.instructors {
+		if instructor.ID == id {
+			return instructor, nil
+		}
+	}
+	return structs.Instructor{}, ErrNotFound
+}
+
+func (s *MemoryStore) CreateRoom(room structs.Room) (structs.Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room.ID = s.roomID
+	s.roomID++
+	s.rooms = append(s.rooms, room)
+	return room, nil
+}
+
+func (s *MemoryStore) ListRooms() ([]structs.Room, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]structs.Room(nil), s.rooms...), nil
+}
+
+func (s *MemoryStore) GetRoom(id int) (structs.Room, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, room := range s.rooms {
+		if room.ID == id {
+			return room, nil
+		}
+	}
+	return structs.Room{}, ErrNotFound
+}
+
+func (s *MemoryStore) CreateStudio(studio structs.Studio) (structs.Studio, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	studio.ID = s.studioID
+	s.studioID++
+	s.studios = append(s.studios, studio)
+	return studio, nil
+}
+
+func (s *MemoryStore) ListStudios() ([]structs.Studio, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]structs.Studio(nil), s.studios...), nil
+}
+
+func (s *MemoryStore) GetStudio(id int) (structs.Studio, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, studio := range s.studios {
+		if studio.ID == id {
+			return studio, nil
+		}
+	}
+	return structs.Studio{}, ErrNotFound
+}
+
+func (s *MemoryStore) UpdateStudio(studio structs.Studio) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.studios {
+		if s.studios[i].ID == studio.ID {
+			s.studios[i] = studio
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// updatedEntry returns the stored entry the booking updates, ErrNotFound when it is missing or
+// cancelled. Waitlist entries can only be cancelled or promoted to booked. Caller must hold s.mu.
+func (s *MemoryStore) updatedEntry(booking structs.Booking) (structs.Booking, error) {
+	current, ok := s.bookingsByID[booking.ID]
+	if !ok || structs.IsCancelled(current.Status) {
+		return structs.Booking{}, ErrNotFound
+	}
+	if current.Status == structs.BookingStatusWaitlisted && booking.Status != structs.BookingStatusBooked && !structs.IsCancelled(booking.Status) {
+		return structs.Booking{}, ErrNotFound
+	}
+	return current, nil
+}
+
+// updateEntry stores the status of the booking or waitlist entry with the booking's ID, caller must hold s.mu.
+// Cancelled entries leave their session and are only kept in the lookups, a promoted waitlist entry
+// moves to the end of the confirmed bookings.
+func (s *MemoryStore) updateEntry(booking structs.Booking) error {
+	current, err := s.updatedEntry(booking)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case current.Status == structs.BookingStatusWaitlisted:
+		if err := removeEntry(s.waitlist, current); err != nil {
+			return err
+		}
+		if !structs.IsCancelled(booking.Status) {
+			appendEntry(s.bookings, booking)
+		}
+	case structs.IsCancelled(booking.Status):
+		if err := removeEntry(s.bookings, current); err != nil {
+			return err
+		}
+	default:
+		if err := replaceEntry(s.bookings, booking); err != nil {
+			return err
+		}
+	}
+	s.index(booking)
+	return nil
+}
+
+// assignBookingID gives the booking a new ID when it has none, caller must hold s.mu
+func (s *MemoryStore) assignBookingID(booking structs.Booking) structs.Booking {
+	if booking.ID == 0 {
+		booking.ID = s.bookingID
+		s.bookingID++
+	}
+	return booking
+}
+
+// index adds the booking to the ID and member lookups, caller must hold s.mu
+func (s *MemoryStore) index(booking structs.Booking) {
+	if _, ok := s.bookingsByID[booking.ID]; !ok {
+		member := structs.NormalizeMemberName(booking.MemberName)
+		s.memberBookings[member] = append(s.memberBookings[member], booking.ID)
+	}
+	s.bookingsByID[booking.ID] = booking
+}
+
+// unindex removes the booking from the ID and member lookups, caller must hold s.mu
+func (s *MemoryStore) unindex(id int) {
+	booking, ok := s.bookingsByID[id]
+	if !ok {
+		return
+	}
+	delete(s.bookingsByID, id)
+
+	member := structs.NormalizeMemberName(booking.MemberName)
+	ids := s.memberBookings[member]
+	for i := range ids {
+		if ids[i] == id {
+			s.memberBookings[member] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(s.memberBookings[member]) == 0 {
+		delete(s.memberBookings, member)
+	}
+}
+
+// appendEntry adds the booking to the date wise map, initializing the date if needed
+func appendEntry(entries map[string]map[string][]structs.Booking, booking structs.Booking) {
+	date := booking.ClassDate.Format(DATEFORMAT)
+	if _, ok := entries[date]; !ok {
+		entries[date] = make(map[string][]structs.Booking)
+	}
+	entries[date][booking.ClassName] = append(entries[date][booking.ClassName], booking)
+}
+
+// sessionEntries returns the entries of the class session starting at classDate from the date wise map
+func sessionEntries(entries map[string]map[string][]structs.Booking, className string, classDate time.Time) []structs.Booking {
+	var result []structs.Booking
+	for _, entry := range entries[classDate.Format(DATEFORMAT)][className] {
+		if entry.ClassDate.Equal(classDate) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// replaceEntry replaces the entry with the booking's ID in the date wise map
+func replaceEntry(entries map[string]map[string][]structs.Booking, booking structs.Booking) error {
+	existing := entries[booking.ClassDate.Format(DATEFORMAT)][booking.ClassName]
+	for i, entry := range existing {
+		if entry.ID == booking.ID {
+			existing[i] = booking
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// removeEntry deletes the entry with the booking's ID from the date wise map
+func removeEntry(entries map[string]map[string][]structs.Booking, booking structs.Booking) error {
+	date := booking.ClassDate.Format(DATEFORMAT)
+	existing := entries[date][booking.ClassName]
+	for i, entry := range existing {
+		if entry.ID == booking.ID {
+			entries[date][booking.ClassName] = append(existing[:i:i], existing[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is a versioned set of schema changes, versions are applied in order and only once
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations lists every schema change of the SQLite backend. Never edit an applied
+// migration, add a new version instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create classes, members, bookings and waitlist tables",
+		statements: []string{
+			`CREATE TABLE classes (
+				id         INTEGER PRIMARY KEY AUTOINCREMENT,
+				class_name TEXT    NOT NULL,
+				start_date TEXT    NOT NULL,
+				end_date   TEXT    NOT NULL,
+				capacity   INTEGER NOT NULL,
+				CHECK (start_date <= end_date)
+			)`,
+			//rejects overlapping dates of classes with the same name. Version 5 limits it to classes
+			//without schedule, every other overlap is only checked by the class processor
+			`CREATE TRIGGER classes_no_overlap BEFORE INSERT ON classes
+			WHEN EXISTS (
+				SELECT 1 FROM classes
+				WHERE class_name = NEW.class_name
+				AND ((NEW.start_date < end_date AND NEW.end_date > start_date)
+					OR NEW.start_date = start_date OR NEW.end_date = end_date)
+			)
+			BEGIN
+				SELECT RAISE(ABORT, 'class date conflicts with existing class schedule');
+			END`,
+			`CREATE TABLE members (
+				id   INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT    NOT NULL UNIQUE
+			)`,
+			`CREATE TABLE bookings (
+				id         INTEGER PRIMARY KEY AUTOINCREMENT,
+				class_name TEXT    NOT NULL,
+				class_date TEXT    NOT NULL,
+				member_id  INTEGER NOT NULL REFERENCES members (id),
+				status     TEXT    NOT NULL,
+				UNIQUE (class_name, class_date, member_id)
+			)`,
+			`CREATE TABLE waitlist (
+				id         INTEGER PRIMARY KEY AUTOINCREMENT,
+				class_name TEXT    NOT NULL,
+				class_date TEXT    NOT NULL,
+				member_id  INTEGER NOT NULL REFERENCES members (id),
+				UNIQUE (class_name, class_date, member_id)
+			)`,
+		},
+	},
+	{
+		version:     2,
+		description: "check class overlaps on update",
+		statements: []string{
+			`CREATE TRIGGER classes_no_overlap_on_update BEFORE UPDATE ON classes
+			WHEN EXISTS (
+				SELECT 1 FROM classes
+				WHERE class_name = NEW.class_name AND id != NEW.id
+				AND ((NEW.start_date < end_date AND NEW.end_date > start_date)
+					OR NEW.start_date = start_date OR NEW.end_date = end_date)
+			)
+			BEGIN
+				SELECT RAISE(ABORT, 'class date conflicts with existing class schedule');
+			END`,
+		},
+	},
+	{
+		version:     3,
+		description: "keep waitlist entries in the bookings table so they share booking ids",
+		statements: []string{
+			//waitlisted rows get ids after the existing bookings, in their FIFO order
+			`INSERT OR IGNORE INTO bookings (class_name, class_date, member_id, status)
+				SELECT class_name, class_date, member_id, 'waitlisted' FROM waitlist ORDER BY id`,
+			`DROP TABLE waitlist`,
+			`CREATE INDEX bookings_member_id ON bookings (member_id)`,
+			`CREATE INDEX bookings_class_date ON bookings (class_date, class_name)`,
+		},
+	},
+	{
+		version:     4,
+		description: "add contact details of registered members",
+		statements: []string{
+			//members created implicitly by name based bookings stay unregistered
+			`ALTER TABLE members ADD COLUMN email TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE members ADD COLUMN phone TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE members ADD COLUMN registered INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     5,
+		description: "add class schedules and book sessions by start time",
+		statements: []string{
+			//the schedule is stored as JSON, NULL keeps the single all day session of older classes
+			`ALTER TABLE classes ADD COLUMN schedule TEXT`,
+			//overlaps between sessions are only checked by the class processor, the triggers keep
+			//guarding classes without schedule by name
+			`DROP TRIGGER classes_no_overlap`,
+			`DROP TRIGGER classes_no_overlap_on_update`,
+			`CREATE TRIGGER classes_no_overlap BEFORE INSERT ON classes
+			WHEN NEW.schedule IS NULL AND EXISTS (
+				SELECT 1 FROM classes
+				WHERE class_name = NEW.class_name AND schedule IS NULL
+				AND ((NEW.start_date < end_date AND NEW.end_date > start_date)
+					OR NEW.start_date = start_date OR NEW.end_date = end_date)
+			)
+			BEGIN
+				SELECT RAISE(ABORT, 'class date conflicts with existing class schedule');
+			END`,
+			`CREATE TRIGGER classes_no_overlap_on_update BEFORE UPDATE ON classes
+			WHEN NEW.schedule IS NULL AND EXISTS (
+				SELECT 1 FROM classes
+				WHERE class_name = NEW.class_name AND id != NEW.id AND schedule IS NULL
+				AND ((NEW.start_date < end_date AND NEW.end_date > start_date)
+					OR NEW.start_date = start_date OR NEW.end_date = end_date)
+			)
+			BEGIN
+				SELECT RAISE(ABORT, 'class date conflicts with existing class schedule');
+			END`,
+			//SQLite cannot change a unique constraint, so bookings are copied into a table
+			//which is unique per session. Existing bookings are for the all day session.
+			`CREATE TABLE bookings_sessions (
+				id         INTEGER PRIMARY KEY AUTOINCREMENT,
+				class_name TEXT    NOT NULL,
+				class_date TEXT    NOT NULL,
+				start_time TEXT    NOT NULL DEFAULT '00:00',
+				member_id  INTEGER NOT NULL REFERENCES members (id),
+				status     TEXT    NOT NULL,
+				UNIQUE (class_name, class_date, start_time, member_id)
+			)`,
+			`INSERT INTO bookings_sessions (id, class_name, class_date, member_id, status)
+				SELECT id, class_name, class_date, member_id, status FROM bookings ORDER BY id`,
+			//ids of deleted bookings are never handed out again
+			`DELETE FROM sqlite_sequence WHERE name = 'bookings_sessions'`,
+			`INSERT INTO sqlite_sequence (name, seq) SELECT 'bookings_sessions', seq FROM sqlite_sequence WHERE name = 'bookings'`,
+			`DROP TABLE bookings`,
+			`ALTER TABLE bookings_sessions RENAME TO bookings`,
+			`CREATE INDEX bookings_member_id ON bookings (member_id)`,
+			`CREATE INDEX bookings_class_date ON bookings (class_date, class_name)`,
+		},
+	},
+	{
+		version:     6,
+		description: "create the studio registry",
+		statements: []string{
+			//only the registry lives here, the data of other studios is kept in databases of their own
+			`CREATE TABLE studios (
+				id       INTEGER PRIMARY KEY AUTOINCREMENT,
+				name     TEXT    NOT NULL,
+				timezone TEXT    NOT NULL
+			)`,
+		},
+	},
+	{
+		version:     7,
+		description: "add cancellation policies of classes and strikes of members",
+		statements: []string{
+			//the policy is stored as JSON, NULL lets members cancel without penalty
+			`ALTER TABLE classes ADD COLUMN cancellation_policy TEXT`,
+			`ALTER TABLE members ADD COLUMN strikes INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     8,
+		description: "add memberships of members and the credits used by bookings",
+		statements: []string{
+			//the membership is stored as JSON, NULL when no plan is assigned
+			`ALTER TABLE members ADD COLUMN membership TEXT`,
+			`ALTER TABLE bookings ADD COLUMN credit_used INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     9,
+		description: "create instructors and rooms and schedule classes with them",
+		statements: []string{
+			`CREATE TABLE instructors (
+				id    INTEGER PRIMARY KEY AUTOINCREMENT,
+				name  TEXT    NOT NULL,
+				email TEXT    NOT NULL DEFAULT ''
+			)`,
+			`CREATE TABLE rooms (
+				id   INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT    NOT NULL
+			)`,
+			//there is no database guard for overlaps on a shared instructor or room, the lock of the
+			//class processor is the only one, so every class change must go through it
+			`ALTER TABLE classes ADD COLUMN instructor_id INTEGER REFERENCES instructors (id)`,
+			`ALTER TABLE classes ADD COLUMN room_id INTEGER REFERENCES rooms (id)`,
+		},
+	},
+	{
+		version:     10,
+		description: "keep cancelled bookings in the booking history of members",
+		statements: []string{
+			//cancelled rows stay in the table, so only bookings which are not cancelled are
+			//unique per session and a member can book again after cancelling
+			`CREATE TABLE bookings_history (
+				id          INTEGER PRIMARY KEY AUTOINCREMENT,
+				class_name  TEXT    NOT NULL,
+				class_date  TEXT    NOT NULL,
+				start_time  TEXT    NOT NULL DEFAULT '00:00',
+				member_id   INTEGER NOT NULL REFERENCES members (id),
+				status      TEXT    NOT NULL,
+				credit_used INTEGER NOT NULL DEFAULT 0
+			)`,
+			`INSERT INTO bookings_history (id, class_name, class_date, start_time, member_id, status, credit_used)
+				SELECT id, class_name, class_date, start_time, member_id, status, credit_used FROM bookings ORDER BY id`,
+			`DELETE FROM sqlite_sequence WHERE name = 'bookings_history'`,
+			`INSERT INTO sqlite_sequence (name, seq) SELECT 'bookings_history', seq FROM sqlite_sequence WHERE name = 'bookings'`,
+			`DROP TABLE bookings`,
+			`ALTER TABLE bookings_history RENAME TO bookings`,
+			`CREATE UNIQUE INDEX bookings_session_member ON bookings (class_name, class_date, start_time, member_id)
+				WHERE status NOT IN ('cancelled', 'late_cancelled')`,
+			`CREATE INDEX bookings_member_id ON bookings (member_id)`,
+			`CREATE INDEX bookings_class_date ON bookings (class_date, class_name)`,
+		},
+	},
+	{
+		version:     11,
+		description: "record which membership assignment paid for a booking",
+		statements: []string{
+			//0 for bookings of plans assigned before memberships were numbered
+			`ALTER TABLE bookings ADD COLUMN credit_assignment INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+}
+
+// migrate applies the pending migrations to the database, each version in its own transaction
+func migrate(db *sql.DB) error {
+	return migrateTo(db, migrations[len(migrations)-1].version)
+}
+
+// migrateTo applies the pending migrations up to and including the target version
+func migrateTo(db *sql.DB, target int) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, statement := range m.statements {
+			if _, err := tx.Exec(statement); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, m.version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaVersion returns the latest applied migration version, 0 for an empty database
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
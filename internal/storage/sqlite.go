@@ -0,0 +1,616 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteStore keeps classes, members, bookings and the studio registry in a SQLite database file. Dates and
+// start times are stored as wall clock values of the studio's location.
+type SQLiteStore struct {
+	db       *sql.DB
+	location *time.Location
+}
+
+// NewSQLiteStore opens the SQLite database at path, creating the file when it does not exist.
+// Stored dates are read in location. Call Migrate before using the store.
+func NewSQLiteStore(path string, location *time.Location) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+
+	//SQLite allows a single writer, sharing one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db, location: location}, nil
+}
+
+// Migrate applies the pending schema migrations
+func (s *SQLiteStore) Migrate() error {
+	return migrate(s.db)
+}
+
+// Check reports an error when the database cannot be reached or misses schema migrations
+func (s *SQLiteStore) Check(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaOutdated, err)
+	}
+	if latest := migrations[len(migrations)-1].version; version < latest {
+		return fmt.Errorf("%w: version %d of %d", ErrSchemaOutdated, version, latest)
+	}
+	return nil
+}
+
+// Close waits for running queries and closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) CreateClass(class structs.Class) (structs.Class, error) {
+	schedule, err := encodeJSON(class.Schedule)
+	if err != nil {
+		return structs.Class{}, err
+	}
+	policy, err := encodeJSON(class.CancellationPolicy)
+	if err != nil {
+		return structs.Class{}, err
+	}
+
+	result, err := s.db.Exec(`INSERT INTO classes (class_name, start_date, end_date, capacity, schedule, cancellation_policy, instructor_id, room_id)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, 0))`,
+		class.ClassName, s.format(class.StartDate, DATEFORMAT), s.format(class.EndDate, DATEFORMAT), class.Capacity, schedule, policy, class.InstructorID, class.RoomID)
+	if err != nil {
+		return structs.Class{}, mapSQLiteError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Class{}, err
+	}
+	class.ID = int(id)
+	return class, nil
+}
+
+func (s *SQLiteStore) ListClasses() ([]structs.Class, error) {
+	return s.queryClasses(classColumns + ` ORDER BY id`)
+}
+
+func (s *SQLiteStore) GetClass(id int) (structs.Class, error) {
+	classes, err := s.queryClasses(classColumns+` WHERE id = ?`, id)
+	if err != nil {
+		return structs.Class{}, err
+	}
+	if len(classes) == 0 {
+		return structs.Class{}, ErrNotFound
+	}
+	return classes[0], nil
+}
+
+func (s *SQLiteStore) UpdateClass(class structs.Class) error {
+	schedule, err := encodeJSON(class.Schedule)
+	if err != nil {
+		return err
+	}
+	policy, err := encodeJSON(class.CancellationPolicy)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`UPDATE classes SET class_name = ?, start_date = ?, end_date = ?, capacity = ?, schedule = ?, cancellation_policy = ?,
+		instructor_id = NULLIF(?, 0), room_id = NULLIF(?, 0) WHERE id = ?`,
+		class.ClassName, s.format(class.StartDate, DATEFORMAT), s.format(class.EndDate, DATEFORMAT), class.Capacity, schedule, policy,
+		class.InstructorID, class.RoomID, class.ID)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+	return expectAffected(result)
+}
+
+func (s *SQLiteStore) DeleteClass(id int) error {
+	result, err := s.db.Exec(`DELETE FROM classes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// classColumns selects the fields of a class
+const classColumns = `SELECT id, class_name, start_date, end_date, capacity, schedule, cancellation_policy,
+	COALESCE(instructor_id, 0), COALESCE(room_id, 0) FROM classes`
+
+// confirmed matches the rows of confirmed bookings, every other row is a waitlist entry or
+// a cancelled booking
+const confirmed = `b.status NOT IN ('waitlisted', 'cancelled', 'late_cancelled')`
+
+// bookingColumns selects the fields of a booking, joined with the member name
+const bookingColumns = `SELECT b.id, CASE WHEN m.registered = 1 THEN m.id ELSE 0 END, b.class_name, b.class_date, b.start_time, m.name, b.status, b.credit_used, b.credit_assignment
+		FROM bookings b JOIN members m ON m.id = b.member_id`
+
+func (s *SQLiteStore) AddBooking(booking structs.Booking) (structs.Booking, error) {
+	return s.insertEntry(booking)
+}
+
+func (s *SQLiteStore) RemoveBooking(booking structs.Booking) error {
+	return s.deleteEntry(booking.ID, false)
+}
+
+func (s *SQLiteStore) UpdateBooking(booking structs.Booking) error {
+	return s.updateEntry(s.db, booking)
+}
+
+func (s *SQLiteStore) ApplyChange(change BookingChange) (structs.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	defer tx.Rollback()
+
+	var added structs.Booking
+	if change.Add != nil {
+		if added, err = s.insertEntryIn(tx, *change.Add); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+	for _, booking := range change.Update {
+		if err := s.updateEntry(tx, booking); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+	for _, member := range change.Members {
+		if err := s.updateMember(tx, member); err != nil {
+			return structs.Booking{}, err
+		}
+	}
+	return added, tx.Commit()
+}
+
+func (s *SQLiteStore) GetBooking(id int) (structs.Booking, error) {
+	bookings, err := s.queryEntries(bookingColumns+` WHERE b.id = ?`, id)
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	if len(bookings) == 0 {
+		return structs.Booking{}, ErrNotFound
+	}
+	return bookings[0], nil
+}
+
+func (s *SQLiteStore) ListBookings(className string, classDate time.Time) ([]structs.Booking, error) {
+	return s.queryEntries(bookingColumns+` WHERE b.class_name = ? AND b.class_date = ? AND b.start_time = ? AND `+confirmed+` ORDER BY b.id`,
+		className, s.format(classDate, DATEFORMAT), s.format(classDate, TIMEFORMAT))
+}
+
+func (s *SQLiteStore) ListBookingsByDate(classDate time.Time) (map[string][]structs.Booking, error) {
+	bookings, err := s.queryEntries(bookingColumns+` WHERE b.class_date = ? AND `+confirmed+` ORDER BY b.id`,
+		s.format(classDate, DATEFORMAT))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]structs.Booking)
+	for _, booking := range bookings {
+		result[booking.ClassName] = append(result[booking.ClassName], booking)
+	}
+	return result, nil
+}
+
+func (s *SQLiteStore) ListBookingsByClass(className string) ([]structs.Booking, error) {
+	return s.queryEntries(bookingColumns+` WHERE b.class_name = ? AND `+confirmed+` ORDER BY b.id`, className)
+}
+
+func (s *SQLiteStore) ListBookingsByMember(memberName string) ([]structs.Booking, error) {
+	return s.queryEntries(bookingColumns+` WHERE lower(trim(m.name)) = ? ORDER BY b.id`, structs.NormalizeMemberName(memberName))
+}
+
+func (s *SQLiteStore) QueryBookings(filter BookingFilter) ([]structs.Booking, error) {
+	query, args := bookingColumns+` WHERE `+confirmed, []any(nil)
+	if !filter.From.IsZero() {
+		query, args = query+` AND b.class_date >= ?`, append(args, s.format(filter.From, DATEFORMAT))
+	}
+	if !filter.To.IsZero() {
+		query, args = query+` AND b.class_date <= ?`, append(args, s.format(filter.To, DATEFORMAT))
+	}
+	if filter.ClassName != "" {
+		query, args = query+` AND b.class_name = ?`, append(args, filter.ClassName)
+	}
+	if filter.MemberName != "" {
+		query, args = query+` AND lower(trim(m.name)) = ?`, append(args, structs.NormalizeMemberName(filter.MemberName))
+	}
+	return s.queryEntries(query+` ORDER BY b.id`, args...)
+}
+
+func (s *SQLiteStore) AddToWaitlist(booking structs.Booking) (structs.Booking, error) {
+	booking.Status = structs.BookingStatusWaitlisted
+	return s.insertEntry(booking)
+}
+
+func (s *SQLiteStore) RemoveFromWaitlist(booking structs.Booking) error {
+	return s.deleteEntry(booking.ID, true)
+}
+
+func (s *SQLiteStore) ListWaitlist(className string, classDate time.Time) ([]structs.Booking, error) {
+	return s.queryEntries(bookingColumns+` WHERE b.class_name = ? AND b.class_date = ? AND b.start_time = ? AND b.status = ? ORDER BY b.id`,
+		className, s.format(classDate, DATEFORMAT), s.format(classDate, TIMEFORMAT), structs.BookingStatusWaitlisted)
+}
+
+// memberColumns selects the fields of a registered member
+const memberColumns = `SELECT id, name, email, phone, strikes, membership FROM members WHERE registered = 1`
+
+func (s *SQLiteStore) CreateMember(member structs.Member) (structs.Member, error) {
+	//a member created implicitly by a name based booking becomes registered
+	result, err := s.db.Exec(`INSERT INTO members (name, email, phone, registered) VALUES (?, ?, ?, 1)
+		ON CONFLICT (name) DO UPDATE SET email = excluded.email, phone = excluded.phone, registered = 1
+		WHERE registered = 0`, member.Name, member.Email, member.Phone)
+	if err != nil {
+		return structs.Member{}, mapSQLiteError(err)
+	}
+	if err := expectAffected(result); err != nil {
+		return structs.Member{}, fmt.Errorf("%w: member name is already registered", ErrConflict)
+	}
+
+	err = s.db.QueryRow(`SELECT id FROM members WHERE name = ?`, member.Name).Scan(&member.ID)
+	return member, err
+}
+
+func (s *SQLiteStore) GetMember(id int) (structs.Member, error) {
+	return s.queryMember(memberColumns+` AND id = ?`, id)
+}
+
+func (s *SQLiteStore) FindMemberByName(name string) (structs.Member, error) {
+	return s.queryMember(memberColumns+` AND lower(trim(name)) = ?`, structs.NormalizeMemberName(name))
+}
+
+func (s *SQLiteStore) UpdateMember(member structs.Member) error {
+	return s.updateMember(s.db, member)
+}
+
+func (s *SQLiteStore) CreateInstructor(instructor structs.Instructor) (structs.Instructor, error) {
+	result, err := s.db.Exec(`INSERT INTO instructors (name, email) VALUES (?, ?)`, instructor.Name, instructor.Email)
+	if err != nil {
+		return structs.Instructor{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Instructor{}, err
+	}
+	instructor.ID = int(id)
+	return instructor, nil
+}
+
+func (s *SQLiteStore) ListInstructors() ([]structs.Instructor, error) {
+	rows, err := s.db.Query(`SELECT id, name, email FROM instructors ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instructors []structs.Instructor
+	for rows.Next() {
+		var instructor structs.Instructor
+		if err := rows.Scan(&instructor.ID, &instructor.Name, &instructor.Email); err != nil {
+			return nil, err
+		}
+		instructors = append(instructors, instructor)
+	}
+	return instructors, rows.Err()
+}
+
+func (s *SQLiteStore) GetInstructor(id int) (structs.Instructor, error) {
+	var instructor structs.Instructor
+	err := s.db.QueryRow(`SELECT id, name, email FROM instructors WHERE id = ?`, id).Scan(&instructor.ID, &instructor.Name, &instructor.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return structs.Instructor{}, ErrNotFound
+	}
+	return instructor, err
+}
+
+func (s *SQLiteStore) CreateRoom(room structs.Room) (structs.Room, error) {
+	result, err := s.db.Exec(`INSERT INTO rooms (name) VALUES (?)`, room.Name)
+	if err != nil {
+		return structs.Room{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Room{}, err
+	}
+	room.ID = int(id)
+	return room, nil
+}
+
+func (s *SQLiteStore) ListRooms() ([]structs.Room, error) {
+	rows, err := s.db.Query(`SELECT id, name FROM rooms ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []structs.Room
+	for rows.Next() {
+		var room structs.Room
+		if err := rows.Scan(&room.ID, &room.Name); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, rows.Err()
+}
+
+func (s *SQLiteStore) GetRoom(id int) (structs.Room, error) {
+	var room structs.Room
+	err := s.db.QueryRow(`SELECT id, name FROM rooms WHERE id = ?`, id).Scan(&room.ID, &room.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return structs.Room{}, ErrNotFound
+	}
+	return room, err
+}
+
+func (s *SQLiteStore) CreateStudio(studio structs.Studio) (structs.Studio, error) {
+	result, err := s.db.Exec(`INSERT INTO studios (name, timezone) VALUES (?, ?)`, studio.Name, studio.Timezone)
+	if err != nil {
+		return structs.Studio{}, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Studio{}, err
+	}
+	studio.ID = int(id)
+	return studio, nil
+}
+
+func (s *SQLiteStore) ListStudios() ([]structs.Studio, error) {
+	rows, err := s.db.Query(`SELECT id, name, timezone FROM studios ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var studios []structs.Studio
+	for rows.Next() {
+		var studio structs.Studio
+		if err := rows.Scan(&studio.ID, &studio.Name, &studio.Timezone); err != nil {
+			return nil, err
+		}
+		studios = append(studios, studio)
+	}
+	return studios, rows.Err()
+}
+
+func (s *SQLiteStore) GetStudio(id int) (structs.Studio, error) {
+	var studio structs.Studio
+	err := s.db.QueryRow(`SELECT id, name, timezone FROM studios WHERE id = ?`, id).Scan(&studio.ID, &studio.Name, &studio.Timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return structs.Studio{}, ErrNotFound
+	}
+	return studio, err
+}
+
+func (s *SQLiteStore) UpdateStudio(studio structs.Studio) error {
+	result, err := s.db.Exec(`UPDATE studios SET name = ?, timezone = ? WHERE id = ?`, studio.Name, studio.Timezone, studio.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// queryMember reads a single member row, ErrNotFound when there is none
+func (s *SQLiteStore) queryMember(query string, args ...any) (structs.Member, error) {
+	var member structs.Member
+	var membership sql.NullString
+	err := s.db.QueryRow(query, args...).Scan(&member.ID, &member.Name, &member.Email, &member.Phone, &member.Strikes, &membership)
+	if errors.Is(err, sql.ErrNoRows) {
+		return structs.Member{}, ErrNotFound
+	}
+	if err == nil && membership.Valid {
+		err = json.Unmarshal([]byte(membership.String), &member.Membership)
+	}
+	return member, err
+}
+
+// insertEntry registers the booking's member when needed and inserts the booking in the
+// same transaction, a booking without ID is assigned the next one
+func (s *SQLiteStore) insertEntry(booking structs.Booking) (structs.Booking, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	defer tx.Rollback()
+
+	if booking, err = s.insertEntryIn(tx, booking); err != nil {
+		return structs.Booking{}, err
+	}
+	return booking, tx.Commit()
+}
+
+// insertEntryIn stores the booking or waitlist entry in the transaction, an unknown member name is added
+// as an unregistered member
+func (s *SQLiteStore) insertEntryIn(tx *sql.Tx, booking structs.Booking) (structs.Booking, error) {
+	if _, err := tx.Exec(`INSERT INTO members (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, booking.MemberName); err != nil {
+		return structs.Booking{}, err
+	}
+
+	result, err := tx.Exec(`INSERT INTO bookings (id, class_name, class_date, start_time, member_id, status, credit_used, credit_assignment)
+		VALUES (NULLIF(?, 0), ?, ?, ?, (SELECT id FROM members WHERE name = ?), ?, ?, ?)`,
+		booking.ID, booking.ClassName, s.format(booking.ClassDate, DATEFORMAT), s.format(booking.ClassDate, TIMEFORMAT), booking.MemberName, booking.Status,
+		booking.CreditUsed, booking.CreditAssignment)
+	if err != nil {
+		return structs.Booking{}, mapSQLiteError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Booking{}, err
+	}
+	booking.ID = int(id)
+	return booking, nil
+}
+
+// updateEntry stores the status of the booking or waitlist entry with the booking's ID, waitlist
+// entries can only be cancelled or promoted to booked
+func (s *SQLiteStore) updateEntry(q execer, booking structs.Booking) error {
+	updatable := confirmed
+	if structs.IsCancelled(booking.Status) || booking.Status == structs.BookingStatusBooked {
+		updatable = `(` + confirmed + ` OR b.status = 'waitlisted')`
+	}
+	result, err := q.Exec(`UPDATE bookings AS b SET status = ? WHERE id = ? AND `+updatable, booking.Status, booking.ID)
+	if err != nil {
+		return mapSQLiteError(err)
+	}
+	return expectAffected(result)
+}
+
+// updateMember replaces the registered member with the member's ID
+func (s *SQLiteStore) updateMember(q execer, member structs.Member) error {
+	membership, err := encodeJSON(member.Membership)
+	if err != nil {
+		return err
+	}
+
+	result, err := q.Exec(`UPDATE members SET email = ?, phone = ?, strikes = ?, membership = ? WHERE id = ? AND registered = 1`,
+		member.Email, member.Phone, member.Strikes, membership, member.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// deleteEntry deletes the waitlist entry, or the confirmed booking, with the id
+func (s *SQLiteStore) deleteEntry(id int, waitlisted bool) error {
+	entries := confirmed
+	if waitlisted {
+		entries = `b.status = 'waitlisted'`
+	}
+	result, err := s.db.Exec(`DELETE FROM bookings AS b WHERE id = ? AND `+entries, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// expectAffected returns ErrNotFound when the statement did not touch any row
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// queryClasses reads rows of id, class name, start date, end date, capacity, schedule and cancellation policy into classes
+func (s *SQLiteStore) queryClasses(query string, args ...any) ([]structs.Class, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var classes []structs.Class
+	for rows.Next() {
+		var class structs.Class
+		var startDate, endDate string
+		var schedule, policy sql.NullString
+		if err := rows.Scan(&class.ID, &class.ClassName, &startDate, &endDate, &class.Capacity, &schedule, &policy,
+			&class.InstructorID, &class.RoomID); err != nil {
+			return nil, err
+		}
+		if class.StartDate, err = time.ParseInLocation(DATEFORMAT, startDate, s.location); err != nil {
+			return nil, err
+		}
+		if class.EndDate, err = time.ParseInLocation(DATEFORMAT, endDate, s.location); err != nil {
+			return nil, err
+		}
+		if schedule.Valid {
+			if err := json.Unmarshal([]byte(schedule.String), &class.Schedule); err != nil {
+				return nil, err
+			}
+		}
+		if policy.Valid {
+			if err := json.Unmarshal([]byte(policy.String), &class.CancellationPolicy); err != nil {
+				return nil, err
+			}
+		}
+		classes = append(classes, class)
+	}
+	return classes, rows.Err()
+}
+
+// queryEntries reads rows of id, member id, class name, class date, start time, member name, status, credit used
+// and credit assignment into bookings
+func (s *SQLiteStore) queryEntries(query string, args ...any) ([]structs.Booking, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []structs.Booking
+	for rows.Next() {
+		var booking structs.Booking
+		var classDate, startTime string
+		if err := rows.Scan(&booking.ID, &booking.MemberID, &booking.ClassName, &classDate, &startTime, &booking.MemberName, &booking.Status, &booking.CreditUsed,
+			&booking.CreditAssignment); err != nil {
+			return nil, err
+		}
+		if booking.ClassDate, err = time.ParseInLocation(DATEFORMAT+" "+TIMEFORMAT, classDate+" "+startTime, s.location); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, rows.Err()
+}
+
+// execer runs statements on the database or inside a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// format formats the time as a wall clock value of the studio's location
+func (s *SQLiteStore) format(t time.Time, layout string) string {
+	return t.In(s.location).Format(layout)
+}
+
+// encodeJSON stores the schedule or cancellation policy of a class, or the membership of a member, as JSON, nil is stored as NULL
+func encodeJSON[T any](value *T) (sql.NullString, error) {
+	if value == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// mapSQLiteError turns constraint violations into ErrConflict so callers do not depend on the driver
+func mapSQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT {
+		return fmt.Errorf("%w: %s", ErrConflict, sqliteErr.Error())
+	}
+	return err
+}
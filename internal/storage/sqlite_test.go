@@ -0,0 +1,385 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+// newTestSQLiteStore opens a migrated store on a temporary database file
+func newTestSQLiteStore(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+
+	store, err := NewSQLiteStore(path, time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return store
+}
+
+func TestSQLiteStore_Migrate(t *testing.T) {
+	store := newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db"))
+
+	// Running the migrations again is a no-op
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	version, err := schemaVersion(store.db)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != migrations[len(migrations)-1].version {
+		t.Fatalf("expected schema version %d, got %d", migrations[len(migrations)-1].version, version)
+	}
+}
+
+func TestSQLiteStore_ClassOverlapConstraint(t *testing.T) {
+	store := newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db"))
+	startDate, _ := time.Parse(DATEFORMAT, "2025-02-20")
+	endDate, _ := time.Parse(DATEFORMAT, "2025-02-28")
+
+	class, err := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: startDate, EndDate: endDate, Capacity: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if class.ID == 0 {
+		t.Fatalf("expected class id to be assigned")
+	}
+
+	overlapStart, _ := time.Parse(DATEFORMAT, "2025-02-25")
+	overlapEnd, _ := time.Parse(DATEFORMAT, "2025-03-05")
+	_, err = store.CreateClass(structs.Class{ClassName: "yoga", StartDate: overlapStart, EndDate: overlapEnd, Capacity: 10})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	// Other classes may use the same dates
+	if _, err := store.CreateClass(structs.Class{ClassName: "pilates", StartDate: overlapStart, EndDate: overlapEnd, Capacity: 10}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Updates are checked against the other classes only
+	later, err := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: overlapEnd, EndDate: overlapEnd, Capacity: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	later.Capacity = 20
+	if err := store.UpdateClass(later); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	later.StartDate = overlapStart
+	if err := store.UpdateClass(later); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	if err := store.DeleteClass(later.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := store.GetClass(later.ID); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestSQLiteStore_DuplicateBookingConstraint(t *testing.T) {
+	store := newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db"))
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+	booking := structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked}
+
+	booking, err := store.AddBooking(booking)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	// A booked member cannot join the waitlist of the same class either
+	if _, err := store.AddToWaitlist(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	if err := store.RemoveBooking(booking); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.RemoveBooking(booking); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+}
+
+func TestSQLiteStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glofox.db")
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	store := newTestSQLiteStore(t, path)
+	store.CreateClass(structs.Class{ClassName: "yoga", StartDate: classDate, EndDate: classDate, Capacity: 1})
+	booked, _ := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	store.AddToWaitlist(structs.Booking{MemberName: "John", ClassName: "yoga", ClassDate: classDate})
+	store.AddToWaitlist(structs.Booking{MemberName: "Jane", ClassName: "yoga", ClassDate: classDate})
+	store.Close()
+
+	// Reopen the database as a restarted server would
+	reopened := newTestSQLiteStore(t, path)
+
+	classes, _ := reopened.ListClasses()
+	if len(classes) != 1 || !classes[0].StartDate.Equal(classDate) {
+		t.Fatalf("expected the yoga class to be reloaded, got %v", classes)
+	}
+
+	bookings, _ := reopened.ListBookingsByDate(classDate)
+	if len(bookings["yoga"]) != 1 || bookings["yoga"][0].MemberName != "Sai Kumar" {
+		t.Fatalf("expected booking of Sai Kumar to be reloaded, got %v", bookings)
+	}
+
+	waiting, _ := reopened.ListWaitlist("yoga", classDate)
+	if len(waiting) != 2 || waiting[0].MemberName != "John" || waiting[1].MemberName != "Jane" {
+		t.Fatalf("expected John and Jane on the waitlist in order, got %v", waiting)
+	}
+
+	found, err := reopened.GetBooking(booked.ID)
+	if err != nil || found.MemberName != "Sai Kumar" {
+		t.Fatalf("expected booking %d of Sai Kumar, got %v, %v", booked.ID, found, err)
+	}
+
+	// Promoting a waitlist entry keeps its id
+	reopened.RemoveFromWaitlist(waiting[0])
+	promoted := waiting[0]
+	promoted.Status = structs.BookingStatusBooked
+	if promoted, err = reopened.AddBooking(promoted); err != nil || promoted.ID != waiting[0].ID {
+		t.Fatalf("expected promoted booking to keep id %d, got %v, %v", waiting[0].ID, promoted, err)
+	}
+
+	memberBookings, _ := reopened.ListBookingsByMember("John")
+	if len(memberBookings) != 1 || memberBookings[0].Status != structs.BookingStatusBooked {
+		t.Fatalf("expected the booked class of John, got %v", memberBookings)
+	}
+}
+
+func TestSQLiteStore_Members(t *testing.T) {
+	store := newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db"))
+	classDate, _ := time.Parse(DATEFORMAT, "2025-02-22")
+
+	// A name based booking creates an unregistered member which can register later
+	store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	if _, err := store.FindMemberByName("Sai Kumar"); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	member, err := store.CreateMember(structs.Member{Name: "Sai Kumar", Email: "sai@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := store.CreateMember(structs.Member{Name: "Sai Kumar", Email: "other@example.com"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	found, err := store.FindMemberByName(" sai KUMAR ")
+	if err != nil || found.ID != member.ID || found.Email != "sai@example.com" {
+		t.Fatalf("expected member %d, got %v, %v", member.ID, found, err)
+	}
+
+	member.Phone = "+14155552671"
+	if err := store.UpdateMember(member); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := store.GetMember(member.ID); found.Phone != member.Phone {
+		t.Fatalf("expected phone %s, got %s", member.Phone, found.Phone)
+	}
+
+	// The earlier booking now belongs to the registered member
+	bookings, _ := store.ListBookingsByMember("Sai Kumar")
+	if len(bookings) != 1 || bookings[0].MemberID != member.ID {
+		t.Fatalf("expected the booking to be linked to member %d, got %v", member.ID, bookings)
+	}
+}
+
+func TestSQLiteStore_Sessions(t *testing.T) {
+	store := newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db"))
+	startDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	schedule := &structs.Schedule{Weekdays: []string{"mon"}, StartTimes: []string{"07:00", "18:30"}, Duration: 60}
+
+	// Classes with a schedule are checked for overlaps by the class processor, not by the database
+	for i := 0; i < 2; i++ {
+		if _, err := store.CreateClass(structs.Class{ClassName: "yoga", StartDate: startDate, EndDate: startDate, Capacity: 10, Schedule: schedule}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	classes, _ := store.ListClasses()
+	if classes[0].Schedule == nil || classes[0].Schedule.StartTimes[1] != "18:30" || classes[0].Schedule.Duration != 60 {
+		t.Fatalf("expected the schedule to be stored, got %v", classes[0].Schedule)
+	}
+
+	// A member can book every session of a day once
+	morning, evening := startDate.Add(7*time.Hour), startDate.Add(18*time.Hour+30*time.Minute)
+	for _, session := range []time.Time{morning, evening} {
+		if _, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: session, Status: structs.BookingStatusBooked}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: morning, Status: structs.BookingStatusBooked}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected %v, got %v", ErrConflict, err)
+	}
+
+	bookings, _ := store.ListBookings("yoga", evening)
+	if len(bookings) != 1 || !bookings[0].ClassDate.Equal(evening) {
+		t.Fatalf("expected one booking for the evening session, got %v", bookings)
+	}
+	if byDate, _ := store.ListBookingsByDate(startDate); len(byDate["yoga"]) != 2 {
+		t.Fatalf("expected both sessions on the date, got %v", byDate)
+	}
+}
+
+func TestSQLiteStore_MigrateSessions(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "glofox.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer store.Close()
+	if err := migrateTo(store.db, 4); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	classDate, _ := time.Parse(DATEFORMAT, "2025-03-03")
+	store.db.Exec(`INSERT INTO members (name) VALUES ('Sai Kumar'), ('John')`)
+	store.db.Exec(`INSERT INTO bookings (class_name, class_date, member_id, status) VALUES
+		('yoga', '2025-03-03', 1, 'booked'), ('yoga', '2025-03-03', 2, 'booked')`)
+	store.db.Exec(`DELETE FROM bookings WHERE member_id = 2`)
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Existing bookings become bookings of the all day session and keep their ids
+	bookings, _ := store.ListBookings("yoga", classDate)
+	if len(bookings) != 1 || bookings[0].ID != 1 || bookings[0].MemberName != "Sai Kumar" {
+		t.Fatalf("expected the booking to be migrated, got %v", bookings)
+	}
+
+	// The id of the deleted booking is not handed out again
+	next, err := store.AddBooking(structs.Booking{MemberName: "Jane", ClassName: "yoga", ClassDate: classDate, Status: structs.BookingStatusBooked})
+	if err != nil || next.ID != 3 {
+		t.Fatalf("expected booking id 3, got %d, %v", next.ID, err)
+	}
+}
+
+func TestSQLiteStore_Location(t *testing.T) {
+	location, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "glofox.db"), location)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// 02:00 in Kolkata is still the previous day in UTC, dates are kept as the studio's dates
+	session := time.Date(2025, 3, 3, 2, 0, 0, 0, location)
+	store.CreateClass(structs.Class{ClassName: "yoga", StartDate: time.Date(2025, 3, 3, 0, 0, 0, 0, location), EndDate: time.Date(2025, 3, 3, 0, 0, 0, 0, location), Capacity: 10})
+	store.AddBooking(structs.Booking{MemberName: "Sai Kumar", ClassName: "yoga", ClassDate: session.UTC(), Status: structs.BookingStatusBooked})
+
+	classes, _ := store.ListClasses()
+	if len(classes) != 1 || classes[0].StartDate.Format(time.RFC3339) != "2025-03-03T00:00:00+05:30" {
+		t.Fatalf("expected the class to start on 2025-03-03 in Kolkata, got %v", classes)
+	}
+
+	bookings, _ := store.ListBookings("yoga", session)
+	if len(bookings) != 1 || bookings[0].ClassDate.Format(time.RFC3339) != "2025-03-03T02:00:00+05:30" {
+		t.Fatalf("expected the booking at 02:00 Kolkata time, got %v", bookings)
+	}
+	if byDate, _ := store.ListBookingsByDate(classes[0].StartDate); len(byDate["yoga"]) != 1 {
+		t.Fatalf("expected the booking on the studio's date, got %v", byDate)
+	}
+}
+
+func TestSQLiteStore_Studios(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glofox.db")
+	store := newTestSQLiteStore(t, path)
+
+	studio, err := store.CreateStudio(structs.Studio{Name: "Dublin", Timezone: "Europe/Dublin"})
+	if err != nil || studio.ID != 1 {
+		t.Fatalf("expected studio 1, got %v, %v", studio, err)
+	}
+
+	studio.Timezone = "Europe/London"
+	if err := store.UpdateStudio(studio); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.UpdateStudio(structs.Studio{ID: 99, Name: "Nowhere", Timezone: "UTC"}); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+	if _, err := store.GetStudio(99); err != ErrNotFound {
+		t.Fatalf("expected %v, got %v", ErrNotFound, err)
+	}
+
+	// Studios survive a restart
+	store.Close()
+	reopened := newTestSQLiteStore(t, path)
+	studios, _ := reopened.ListStudios()
+	if len(studios) != 1 || studios[0].Timezone != "Europe/London" {
+		t.Fatalf("expected the London studio, got %v", studios)
+	}
+}
+
+func TestSQLiteStore_Check(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "glofox.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	// A database without every migration is not ready
+	if err := store.Check(context.Background()); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("expected %v, got %v", ErrSchemaOutdated, err)
+	}
+	if err := migrateTo(store.db, 4); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.Check(context.Background()); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("expected %v, got %v", ErrSchemaOutdated, err)
+	}
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := store.Check(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	store.Close()
+	if err := store.Check(context.Background()); err == nil {
+		t.Fatalf("expected an error for a closed database")
+	}
+}
+
+func TestSQLiteStore_QueryBookings(t *testing.T) {
+	checkQueryBookings(t, newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db")))
+}
+
+func TestSQLiteStore_Attendance(t *testing.T) {
+	checkAttendance(t, newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db")))
+}
+
+func TestSQLiteStore_Cancellations(t *testing.T) {
+	checkCancellations(t, newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db")))
+}
+
+func TestSQLiteStore_Memberships(t *testing.T) {
+	checkMemberships(t, newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db")))
+}
+
+func TestSQLiteStore_Resources(t *testing.T) {
+	checkResources(t, newTestSQLiteStore(t, filepath.Join(t.TempDir(), "glofox.db")))
+}
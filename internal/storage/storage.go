@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saikumar-neelam/glofox_studio/internal/structs"
+)
+
+const (
+	DATEFORMAT = "2006-01-02"
+	TIMEFORMAT = "15:04"
+)
+
+// ClassRepository stores the classes created by studio owners
+type ClassRepository interface {
+	// CreateClass stores the class and returns it with its assigned ID
+	CreateClass(class structs.Class) (structs.Class, error)
+	// ListClasses returns every stored class
+	ListClasses() ([]structs.Class, error)
+	// GetClass returns the class with the ID, ErrNotFound when it does not exist
+	GetClass(id int) (structs.Class, error)
+	// UpdateClass replaces the stored class with the same ID
+	UpdateClass(class structs.Class) error
+	// DeleteClass removes the class with the ID
+	DeleteClass(id int) error
+}
+
+// BookingRepository stores the bookings and waitlists of class sessions, grouped by date and class name.
+// Every booking and waitlist entry has a unique ID which it keeps when promoted from the waitlist.
+type BookingRepository interface {
+	// AddBooking stores a confirmed booking, a booking without ID is assigned a new one
+	AddBooking(booking structs.Booking) (structs.Booking, error)
+	// RemoveBooking deletes the confirmed booking with the booking's ID
+	RemoveBooking(booking structs.Booking) error
+	// UpdateBooking replaces the confirmed booking with the booking's ID, it keeps its place in the booking order.
+	// A booking or waitlist entry updated to a cancelled status leaves its session, it is then only
+	// returned by GetBooking and ListBookingsByMember. A waitlist entry updated to booked is promoted
+	// into the confirmed bookings of its session.
+	UpdateBooking(booking structs.Booking) error
+	// ApplyChange stores every write of the change or none of them, added is the stored Add
+	ApplyChange(change BookingChange) (added structs.Booking, err error)
+	// GetBooking returns the booking, waitlist entry or cancelled booking with the ID, ErrNotFound when it does not exist
+	GetBooking(id int) (structs.Booking, error)
+	// ListBookings returns the confirmed bookings of the class session starting at classDate in booking order,
+	// confirmed bookings are booked, attended or no_show
+	ListBookings(className string, classDate time.Time) ([]structs.Booking, error)
+	// ListBookingsByDate returns the bookings on the date grouped by class name
+	ListBookingsByDate(classDate time.Time) (map[string][]structs.Booking, error)
+	// ListBookingsByClass returns the bookings of the class on every date
+	ListBookingsByClass(className string) ([]structs.Booking, error)
+	// ListBookingsByMember returns the bookings, waitlist entries and cancelled bookings of the member, names are
+	// matched after structs.NormalizeMemberName
+	ListBookingsByMember(memberName string) ([]structs.Booking, error)
+	// QueryBookings returns the bookings matching every set field of the filter, bookings of the
+	// same class session are in booking order
+	QueryBookings(filter BookingFilter) ([]structs.Booking, error)
+
+	// AddToWaitlist appends the booking to the end of the class waitlist, a booking without ID is assigned a new one
+	AddToWaitlist(booking structs.Booking) (structs.Booking, error)
+	// RemoveFromWaitlist deletes the waitlist entry with the booking's ID
+	RemoveFromWaitlist(booking structs.Booking) error
+	// ListWaitlist returns the waitlist of the class session starting at classDate in FIFO order
+	ListWaitlist(className string, classDate time.Time) ([]structs.Booking, error)
+}
+
+// BookingFilter selects bookings by date, class and member, zero fields match every booking
+type BookingFilter struct {
+	From       time.Time //first date of the bookings
+	To         time.Time //last date of the bookings
+	ClassName  string
+	MemberName string //matched after structs.NormalizeMemberName
+}
+
+// BookingChange is a booking, the status changes and the member updates it causes, e.g. a
+// cancellation with the waitlist promotion and the credit refund, which are stored in one step
+type BookingChange struct {
+	Add     *structs.Booking  //stored like AddToWaitlist when waitlisted, otherwise like AddBooking
+	Update  []structs.Booking //stored like UpdateBooking
+	Members []structs.Member  //stored like UpdateMember
+}
+
+// MemberRepository stores the registered members of the studio
+type MemberRepository interface {
+	// CreateMember stores the member and returns it with its assigned ID
+	CreateMember(member structs.Member) (structs.Member, error)
+	// GetMember returns the member with the ID, ErrNotFound when it does not exist
+	GetMember(id int) (structs.Member, error)
+	// FindMemberByName returns the member whose name matches after structs.NormalizeMemberName
+	FindMemberByName(name string) (structs.Member, error)
+	// UpdateMember replaces the stored member with the same ID
+	UpdateMember(member structs.Member) error
+}
+
+// ResourceRepository stores the instructors and rooms classes are scheduled with
+type ResourceRepository interface {
+	// CreateInstructor stores the instructor and returns it with its assigned ID
+	CreateInstructor(instructor structs.Instructor) (structs.Instructor, error)
+	// ListInstructors returns every stored instructor
+	ListInstructors() ([]structs.Instructor, error)
+	// GetInstructor returns the instructor with the ID, ErrNotFound when it does not exist
+	GetInstructor(id int) (structs.Instructor, error)
+	// CreateRoom stores the room and returns it with its assigned ID
+	CreateRoom(room structs.Room) (structs.Room, error)
+	// ListRooms returns every stored room
+	ListRooms() ([]structs.Room, error)
+	// GetRoom returns the room with the ID, ErrNotFound when it does not exist
+	GetRoom(id int) (structs.Room, error)
+}
+
+// StudioRepository stores the registry of studios. The classes, bookings and members of a studio
+// are kept in a Store of their own, so studios never share data.
+type StudioRepository interface {
+	// CreateStudio stores the studio and returns it with its assigned ID
+	CreateStudio(studio structs.Studio) (structs.Studio, error)
+	// ListStudios returns every stored studio
+	ListStudios() ([]structs.Studio, error)
+	// GetStudio returns the studio with the ID, ErrNotFound when it does not exist
+	GetStudio(id int) (structs.Studio, error)
+	// UpdateStudio replaces the stored studio with the same ID
+	UpdateStudio(studio structs.Studio) error
+}
+
+var (
+	// ErrNotFound is returned when the requested entry does not exist in the store
+	ErrNotFound = errors.New("entry not found in storage")
+	// ErrConflict is returned when a write violates a uniqueness or schedule constraint of the store
+	ErrConflict = errors.New("entry conflicts with existing data")
+	// ErrSchemaOutdated is returned by Check when the database misses schema migrations
+	ErrSchemaOutdated = errors.New("database schema is not up to date")
+)
+
+// Store is a storage backend which holds classes, bookings, members, instructors and rooms
+type Store interface {
+	ClassRepository
+	BookingRepository
+	MemberRepository
+	ResourceRepository
+
+	// Check reports why the store cannot serve requests, e.g. an unreachable database
+	Check(ctx context.Context) error
+	// Close flushes pending writes and releases the store, closing a closed store does nothing
+	Close() error
+}
@@ -1,6 +1,16 @@
 package structs
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// Studio represents a studio, every studio has its own classes, bookings and members
+type Studio struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"` //IANA timezone, e.g. Europe/Dublin
+}
 
 // Class represents a studio class
 type Class struct {
@@ -9,23 +19,231 @@ type Class struct {
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
 	Capacity  int       `json:"capacity"`
+	Schedule  *Schedule `json:"schedule,omitempty"` //nil runs a single all day session on every date
+
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty"` //nil lets members cancel without penalty
+
+	InstructorID int `json:"instructor_id,omitempty"` //0 when the class has no instructor
+	RoomID       int `json:"room_id,omitempty"`       //0 when the class has no room
+}
+
+// Instructor teaches classes, an instructor cannot teach two sessions at the same time
+type Instructor struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// Room hosts classes, a room cannot host two sessions at the same time
+type Room struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CancellationPolicy decides which cancellations of a class are late. A late cancellation
+// gives the member a strike, like a no-show.
+type CancellationPolicy struct {
+	LateCancelHours int `json:"late_cancel_hours" validate:"min=0,max=168"` //cancellations closer than this to the session start are late
+}
+
+// Schedule describes the sessions of a class between its start and end date. Sessions start
+// at each of the start times on the weekdays, or on the days of the recurrence rule when set.
+type Schedule struct {
+	Weekdays   []string `json:"weekdays,omitempty" validate:"omitempty,dive,oneof=mon tue wed thu fri sat sun"` //empty runs every day
+	StartTimes []string `json:"start_times" validate:"required,min=1,dive,timeformat"`                          //HH:MM
+	Duration   int      `json:"duration" validate:"required,min=1,max=1440"`                                    //minutes
+	RRule      string   `json:"rrule,omitempty"`                                                                //RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO,WE
+}
+
+// Booking statuses. A booking is booked or waitlisted until it is cancelled, late_cancelled when
+// cancelled inside the cancellation window of the class, and attended or no_show once the
+// session started.
+const (
+	BookingStatusBooked        = "booked"
+	BookingStatusWaitlisted    = "waitlisted"
+	BookingStatusCancelled     = "cancelled"
+	BookingStatusLateCancelled = "late_cancelled"
+	BookingStatusAttended      = "attended"
+	BookingStatusNoShow        = "no_show"
+)
+
+// IsCancelled reports whether the status is cancelled or late_cancelled, a cancelled booking
+// holds no spot and is only kept in the booking history of its member
+func IsCancelled(status string) bool {
+	return status == BookingStatusCancelled || status == BookingStatusLateCancelled
+}
+
+// NormalizeMemberName trims and case folds a member name, names with the same
+// normalized form belong to the same member
+func NormalizeMemberName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
 
 type Booking struct {
-	MemberName string    `json:"member_name"`
-	ClassDate  time.Time `json:"class_date"`
+	ID               int       `json:"id"`                  //unique identifier, kept when a waitlisted booking is promoted
+	MemberID         int       `json:"member_id,omitempty"` //set when the member is registered
+	MemberName       string    `json:"member_name"`
+	ClassDate        time.Time `json:"class_date"` //start of the booked session
+	ClassName        string    `json:"class_name"`
+	Status           string    `json:"status"`
+	WaitlistPosition int       `json:"waitlist_position,omitempty"` //1 based position, only set while waitlisted
+	CreditUsed       bool      `json:"credit_used,omitempty"`       //a credit of the member's membership paid for the booking
+	CreditAssignment int       `json:"credit_assignment,omitempty"` //Membership.Assignment of the plan which paid, refunds only go back to it
+}
+
+// BookingPage is a page of bookings, NextCursor fetches the next page and is empty on the last page
+type BookingPage struct {
+	Bookings   []Booking `json:"bookings"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// Occupancy counts the booked and waitlisted members of a class session
+type Occupancy struct {
+	ClassID    int       `json:"class_id"`
 	ClassName  string    `json:"class_name"`
+	ClassDate  time.Time `json:"class_date"` //start of the session
+	Capacity   int       `json:"capacity"`
+	Booked     int       `json:"booked"`
+	Waitlisted int       `json:"waitlisted"`
+	Remaining  int       `json:"remaining"` //spots left before the class is full
+}
+
+// Roster lists the confirmed bookings of a class session for the front desk
+type Roster struct {
+	ClassID   int           `json:"class_id"`
+	ClassName string        `json:"class_name"`
+	ClassDate time.Time     `json:"class_date"` //start of the session
+	Capacity  int           `json:"capacity"`
+	Members   []RosterEntry `json:"members"` //in booking order
+}
+
+// RosterEntry is a booked member of a roster and whether the member checked in
+type RosterEntry struct {
+	BookingID  int    `json:"booking_id"`
+	MemberID   int    `json:"member_id,omitempty"` //set when the member is registered
+	MemberName string `json:"member_name"`
+	Status     string `json:"status"` //booked, attended or no_show
+	CheckedIn  bool   `json:"checked_in"`
+}
+
+// Member represents a registered studio member
+type Member struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+
+	Strikes int `json:"strikes"` //late cancellations and no-shows since the strikes were last reset
+
+	Membership *Membership `json:"membership,omitempty"` //nil when no plan is assigned
+}
+
+// Membership plans
+const (
+	PlanMonthly   = "monthly"   //a number of classes per calendar month
+	PlanUnlimited = "unlimited" //any number of classes
+	PlanPack      = "pack"      //a number of classes until the pack expires
+)
+
+// Membership is the plan assigned to a member, every booking uses a credit of it.
+// Credits are used for the month of the session and sessions after ExpiresOn cannot use the plan.
+type Membership struct {
+	Plan      string         `json:"plan"`
+	Credits   int            `json:"credits,omitempty"` //monthly allowance, or the credits left of a pack
+	ExpiresOn *time.Time     `json:"expires_on,omitempty"`
+	Used      map[string]int `json:"used,omitempty"` //credits used of a monthly plan by month, YYYY-MM
+
+	Assignment int `json:"assignment,omitempty"` //numbers the plans assigned to the member, each assignment gets the next number
+}
+
+// Balance is what a member can still book with their membership
+type Balance struct {
+	MemberID  int        `json:"member_id"`
+	Plan      string     `json:"plan"`
+	Period    string     `json:"period,omitempty"`    //month of the remaining credits of a monthly plan, YYYY-MM
+	Remaining *int       `json:"remaining,omitempty"` //credits left, nil for unlimited plans
+	ExpiresOn *time.Time `json:"expires_on,omitempty"`
 }
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Details string `json:"details,omitempty"`
-	Status  int    `json:"status"`
+	Error   string       `json:"error"`
+	Code    string       `json:"code"` //stable machine readable code, e.g. class_full
+	Details string       `json:"details,omitempty"`
+	Status  int          `json:"status"`
+	Fields  []FieldError `json:"fields,omitempty"` //every invalid field of the request
+}
+
+// ProblemDetails is an RFC 7807 error response, sent instead of ErrorResponse to clients
+// which accept application/problem+json
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Fields   []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes a request field which failed a validation rule, field is the JSON name
+// of the field, e.g. class_name or schedule.start_times[0]
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 type ClassRequest struct {
-	ClassName string `json:"class_name" validate:"required"`
-	StartDate string `json:"start_date" validate:"required,dateformat"`
-	EndDate   string `json:"end_date" validate:"required,dateformat"`
-	Capacity  int    `json:"capacity" validate:"required"`
+	ClassName string    `json:"class_name" validate:"required"`
+	StartDate string    `json:"start_date" validate:"required,dateformat"`
+	EndDate   string    `json:"end_date" validate:"required,dateformat"`
+	Capacity  int       `json:"capacity" validate:"required,min=1"`
+	Schedule  *Schedule `json:"schedule" validate:"omitempty"`
+
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy" validate:"omitempty"`
+
+	InstructorID int `json:"instructor_id" validate:"omitempty,min=1"`
+	RoomID       int `json:"room_id" validate:"omitempty,min=1"`
+}
+
+// UpdateClassRequest holds the class fields which can be changed, omitted fields are left unchanged
+type UpdateClassRequest struct {
+	StartDate *string `json:"start_date" validate:"omitempty,dateformat"`
+	EndDate   *string `json:"end_date" validate:"omitempty,dateformat"`
+	Capacity  *int    `json:"capacity" validate:"omitempty,min=1"`
+}
+
+type StudioRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Timezone string `json:"timezone" validate:"required,timezone"`
+}
+
+type InstructorRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+type RoomRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type MemberRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+	Phone string `json:"phone" validate:"omitempty,e164"`
+}
+
+// MembershipRequest assigns a plan to a member, credits are the monthly allowance or the
+// credits of a pack and packs must expire
+type MembershipRequest struct {
+	Plan      string `json:"plan" validate:"required,oneof=monthly unlimited pack"`
+	Credits   int    `json:"credits" validate:"min=0"`
+	ExpiresOn string `json:"expires_on" validate:"omitempty,dateformat"`
+}
+
+// UpdateMemberRequest holds the member fields which can be changed, omitted fields are left unchanged.
+// The name cannot be changed as bookings refer to the member by name.
+type UpdateMemberRequest struct {
+	Email *string `json:"email" validate:"omitempty,email"`
+	Phone *string `json:"phone" validate:"omitempty,e164"`
 }
@@ -1,26 +1,64 @@
 package utils
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 )
 
-// Create custom loggers with different levels
-var (
-	InfoLogger    *log.Logger
-	WarningLogger *log.Logger
-	ErrorLogger   *log.Logger
-)
+// LogOptions configures the logger created by NewLogger
+type LogOptions struct {
+	Level  string //debug, info, warn or error
+	Format string //text or json
+	File   string //file the records are appended to, stdout when empty
+}
+
+// loggerKey is the context key of the request logger
+type loggerKey struct{}
+
+// discardLogger is used when no logger was injected, e.g. handlers called directly in tests
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// NewLogger creates a logger of the options, close closes its log file
+func NewLogger(options LogOptions) (logger *slog.Logger, close func() error, err error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(options.Level)); err != nil {
+		return nil, nil, fmt.Errorf("unknown log level %q, use debug, info, warn or error", options.Level)
+	}
 
-func init() {
-	// Set custom output for the loggers (you can log to different files or locations)
-	file, err := os.OpenFile("./app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalln("Failed to open log file:", err)
+	var out io.Writer = os.Stdout
+	close = func() error { return nil }
+	if options.File != "" {
+		file, err := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, close = file, file.Close
 	}
 
-	// Create loggers with different levels
-	InfoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	WarningLogger = log.New(file, "WARNING: ", log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLogger = log.New(file, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch options.Format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, handlerOptions)), close, nil
+	case "text":
+		return slog.New(slog.NewTextHandler(out, handlerOptions)), close, nil
+	default:
+		close()
+		return nil, nil, fmt.Errorf("unknown log format %q, use text or json", options.Format)
+	}
+}
+
+// NewContext returns a copy of ctx which carries the logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, records are discarded when it carries none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return discardLogger
 }
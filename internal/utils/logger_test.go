@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, close, err := NewLogger(LogOptions{Level: "warn", Format: "json", File: path})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	logger.Info("not written")
+	logger.Warn("written", "class_name", "yoga")
+	if err := close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one line at level warn, got %q", content)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil || record["msg"] != "written" || record["class_name"] != "yoga" {
+		t.Fatalf("expected a JSON record, got %q, %v", lines[0], err)
+	}
+}
+
+func TestNewLogger_Invalid(t *testing.T) {
+	for _, options := range []LogOptions{
+		{Level: "verbose", Format: "text"},
+		{Level: "info", Format: "xml"},
+		{Level: "info", Format: "text", File: filepath.Join(t.TempDir(), "missing", "app.log")},
+	} {
+		if _, _, err := NewLogger(options); err == nil {
+			t.Errorf("expected an error for %+v", options)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != discardLogger {
+		t.Fatalf("expected the discarding logger without an injected one")
+	}
+
+	logger, _, _ := NewLogger(LogOptions{Level: "info", Format: "text"})
+	if FromContext(NewContext(context.Background(), logger)) != logger {
+		t.Fatalf("expected the injected logger")
+	}
+}
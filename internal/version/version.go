@@ -0,0 +1,53 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build details, set at build time with
+//
+//	go build -ldflags "-X github.com/saikumar-neelam/glofox_studio/internal/version.Version=v1.2.0
+//	  -X github.com/saikumar-neelam/glofox_studio/internal/version.Commit=$(git rev-parse HEAD)
+//	  -X github.com/saikumar-neelam/glofox_studio/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Details which are not set are taken from the build info the go command embeds.
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the details of the running build, unknown details are "unknown"
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	for _, detail := range []*string{&info.Version, &info.Commit, &info.BuildTime} {
+		if *detail == "" {
+			*detail = "unknown"
+		}
+	}
+	return info
+}
@@ -0,0 +1,21 @@
+package version
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+
+	Version, Commit, BuildTime = "v1.2.0", "4f2c1e9", "2025-03-01T10:00:00Z"
+	info := Get()
+	if info.Version != "v1.2.0" || info.Commit != "4f2c1e9" || info.BuildTime != "2025-03-01T10:00:00Z" || info.GoVersion == "" {
+		t.Fatalf("expected the build details set by ldflags, got %+v", info)
+	}
+
+	// Details which are not set are never empty
+	Version, Commit, BuildTime = "", "", ""
+	if info := Get(); info.Version == "" || info.Commit == "" || info.BuildTime == "" {
+		t.Fatalf("expected every detail to be set, got %+v", info)
+	}
+}